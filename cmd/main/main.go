@@ -24,7 +24,23 @@ func main() {
 
 	log.Info("logger was initialized", slog.Any("cfg", cfg))
 
-	application := app.New(log, cfg.GRPC, cfg.Storage, cfg.UserProvider, cfg.Kafka, cfg.EventWorker)
+	application := app.New(
+		log,
+		cfg.GRPC,
+		cfg.Storage,
+		cfg.UserProvider,
+		cfg.Kafka,
+		cfg.EventWorker,
+		cfg.Themes,
+		cfg.TrendWorker,
+		cfg.Purger,
+		cfg.Scheduler,
+		cfg.Attachments,
+		cfg.Thumbnailer,
+		cfg.Reactions,
+		cfg.Hashtags,
+		cfg.Views,
+	)
 
 	application.Start()
 
@@ -6,13 +6,34 @@ import (
 	"sync"
 
 	grpcapp "github.com/IlianBuh/Post-service/internal/app/app"
+	cfgAttachments "github.com/IlianBuh/Post-service/internal/config/attachments"
 	cfgEventWorker "github.com/IlianBuh/Post-service/internal/config/event-worker"
 	"github.com/IlianBuh/Post-service/internal/config/grpcobj"
+	cfgHashtags "github.com/IlianBuh/Post-service/internal/config/hashtags"
 	cfgKafka "github.com/IlianBuh/Post-service/internal/config/kafka"
+	cfgPurger "github.com/IlianBuh/Post-service/internal/config/purger"
+	cfgReactions "github.com/IlianBuh/Post-service/internal/config/reactions"
+	cfgScheduler "github.com/IlianBuh/Post-service/internal/config/scheduler"
 	cfgStorage "github.com/IlianBuh/Post-service/internal/config/storage"
+	cfgThemes "github.com/IlianBuh/Post-service/internal/config/themes"
+	cfgThumbnailer "github.com/IlianBuh/Post-service/internal/config/thumbnailer"
+	cfgTrendWorker "github.com/IlianBuh/Post-service/internal/config/trend-worker"
 	cfgUsrPrvdr "github.com/IlianBuh/Post-service/internal/config/user-provider"
+	cfgViews "github.com/IlianBuh/Post-service/internal/config/views"
+	"github.com/IlianBuh/Post-service/internal/lib/hashtags"
+	"github.com/IlianBuh/Post-service/internal/service/attachments"
+	"github.com/IlianBuh/Post-service/internal/service/bookmarks"
+	"github.com/IlianBuh/Post-service/internal/service/comments"
 	eventworker "github.com/IlianBuh/Post-service/internal/service/event-worker"
 	"github.com/IlianBuh/Post-service/internal/service/posts"
+	"github.com/IlianBuh/Post-service/internal/service/purger"
+	"github.com/IlianBuh/Post-service/internal/service/reactions"
+	"github.com/IlianBuh/Post-service/internal/service/scheduler"
+	"github.com/IlianBuh/Post-service/internal/service/themes"
+	"github.com/IlianBuh/Post-service/internal/service/thumbnailer"
+	trendworker "github.com/IlianBuh/Post-service/internal/service/trend-worker"
+	"github.com/IlianBuh/Post-service/internal/service/views"
+	"github.com/IlianBuh/Post-service/internal/storage/localfs"
 	"github.com/IlianBuh/Post-service/internal/storage/postgres"
 	"github.com/IlianBuh/Post-service/internal/transport/kafka"
 	userprovider "github.com/IlianBuh/Post-service/internal/transport/user-provider"
@@ -22,6 +43,11 @@ type App struct {
 	log           *slog.Logger
 	DB            *postgres.Storage
 	EventWorker   *eventworker.Worker
+	TrendWorker   *trendworker.Worker
+	Purger        *purger.Worker
+	Scheduler     *scheduler.Worker
+	Thumbnailer   *thumbnailer.Worker
+	ViewCounter   *views.Counter
 	GRPCApp       *grpcapp.App
 	EventProducer *kafka.Producer
 	UserProvider  *userprovider.UserProvider
@@ -34,6 +60,15 @@ func New(
 	cfgUsrPrvdr cfgUsrPrvdr.Config,
 	cfgKafka cfgKafka.Config,
 	cfgEventWorker cfgEventWorker.Config,
+	cfgThemes cfgThemes.Config,
+	cfgTrendWorker cfgTrendWorker.Config,
+	cfgPurger cfgPurger.Config,
+	cfgScheduler cfgScheduler.Config,
+	cfgAttachments cfgAttachments.Config,
+	cfgThumbnailer cfgThumbnailer.Config,
+	cfgReactions cfgReactions.Config,
+	cfgHashtags cfgHashtags.Config,
+	cfgViews cfgViews.Config,
 ) *App {
 	const op = "app.New"
 	fail := func(err error) {
@@ -52,6 +87,12 @@ func New(
 		fail(err)
 	}
 
+	// TODO : init blob store
+	blobs, err := localfs.New(cfgAttachments.Dir)
+	if err != nil {
+		fail(err)
+	}
+
 	// TODO : init user provider
 	usrPrvdr, err := userprovider.New(
 		log,
@@ -64,10 +105,56 @@ func New(
 	}
 
 	postService := posts.New(
-		log, repo, repo, repo, cfgGRPC.Timeout.Duration, usrPrvdr,
+		log, repo, repo, repo, repo, repo, repo, cfgGRPC.Timeout.Duration, usrPrvdr,
+		hashtags.Options{
+			MaxThemes:  cfgHashtags.MaxThemes,
+			MinLength:  cfgHashtags.MinLength,
+			SplitWords: cfgHashtags.SplitWords,
+		},
+		cfgPurger.IdempotencyTTL.Duration,
+	)
+
+	themeService := themes.New(
+		log, repo, repo, repo, cfgThemes.Admins, cfgGRPC.Timeout.Duration,
+	)
+
+	attachmentService := attachments.New(
+		log, repo, repo, blobs, cfgAttachments.MaxSize, cfgGRPC.Timeout.Duration,
+	)
+
+	reactionService := reactions.New(
+		log, repo, repo, usrPrvdr, cfgReactions.Kinds, cfgGRPC.Timeout.Duration,
+	)
+
+	commentService := comments.New(
+		log, repo, repo, repo, usrPrvdr, cfgGRPC.Timeout.Duration,
+	)
+
+	bookmarkService := bookmarks.New(
+		log, repo, repo, usrPrvdr, cfgGRPC.Timeout.Duration,
 	)
 
-	grpcapp := grpcapp.New(log, cfgGRPC.Port, postService, cfgGRPC.Timeout.Duration)
+	// TODO : init view counter
+	viewCounter := views.New(
+		log,
+		repo,
+		cfgViews.Interval.Duration,
+		cfgViews.BatchSize,
+		cfgViews.DedupWindow.Duration,
+	)
+
+	grpcapp := grpcapp.New(
+		log,
+		cfgGRPC.Port,
+		postService,
+		themeService,
+		attachmentService,
+		reactionService,
+		commentService,
+		bookmarkService,
+		viewCounter,
+		cfgGRPC.Timeout.Duration,
+	)
 
 	// TODO : init kafka producer
 	producer, err := kafka.NewProducer(
@@ -92,12 +179,51 @@ func New(
 		cfgEventWorker.Interval.Duration,
 	)
 
+	// TODO : init trend-worker
+	trendWorker := trendworker.New(log, repo, cfgTrendWorker.Interval.Duration)
+
+	// TODO : init purger
+	trashPurger := purger.New(
+		log,
+		repo,
+		blobs,
+		cfgPurger.Interval.Duration,
+		cfgPurger.Retention.Duration,
+		cfgPurger.IdempotencyTTL.Duration,
+		cfgPurger.BatchSize,
+	)
+
+	// TODO : init scheduler
+	postScheduler := scheduler.New(
+		log,
+		repo,
+		cfgScheduler.Interval.Duration,
+		cfgScheduler.BatchSize,
+	)
+
+	// TODO : init thumbnailer
+	imageWorker := thumbnailer.New(
+		log,
+		repo,
+		blobs,
+		cfgThumbnailer.Interval.Duration,
+		cfgThumbnailer.BatchSize,
+		cfgThumbnailer.Sizes,
+		cfgThumbnailer.MaxPixels,
+		cfgThumbnailer.MaxAttempts,
+	)
+
 	return &App{
 		log:           log,
 		UserProvider:  usrPrvdr,
 		DB:            repo,
 		GRPCApp:       grpcapp,
 		EventWorker:   worker,
+		TrendWorker:   trendWorker,
+		Purger:        trashPurger,
+		Scheduler:     postScheduler,
+		Thumbnailer:   imageWorker,
+		ViewCounter:   viewCounter,
 		EventProducer: producer,
 	}
 }
@@ -108,6 +234,11 @@ func (a *App) Start() {
 	log.Info("starting application")
 
 	a.EventWorker.Start(context.Background())
+	a.TrendWorker.Start(context.Background())
+	a.Purger.Start(context.Background())
+	a.Scheduler.Start(context.Background())
+	a.Thumbnailer.Start(context.Background())
+	a.ViewCounter.Start(context.Background())
 
 	go a.GRPCApp.MustRun()
 
@@ -119,24 +250,47 @@ func (a *App) Stop() {
 	log := a.log.With(slog.String("op", op))
 	log.Info("stopping application")
 
+	// pending views are flushed after the server stops
+	// receiving them and before the storage is closed
+	a.GRPCApp.Stop()
+	a.ViewCounter.Stop()
+
+	// workers are stopped before the storage and the producer are closed,
+	// so the running tick is not cut off
 	var wg sync.WaitGroup
 
 	wg.Add(5)
 	go func() {
 		defer wg.Done()
-		a.EventProducer.Stop()
+		a.EventWorker.Stop()
 	}()
 	go func() {
 		defer wg.Done()
-		a.EventWorker.Stop()
+		a.TrendWorker.Stop()
 	}()
 	go func() {
 		defer wg.Done()
-		a.DB.Stop()
+		a.Purger.Stop()
+	}()
+	go func() {
+		defer wg.Done()
+		a.Scheduler.Stop()
 	}()
 	go func() {
 		defer wg.Done()
-		a.GRPCApp.Stop()
+		a.Thumbnailer.Stop()
+	}()
+
+	wg.Wait()
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		a.EventProducer.Stop()
+	}()
+	go func() {
+		defer wg.Done()
+		a.DB.Stop()
 	}()
 	go func() {
 		defer wg.Done()
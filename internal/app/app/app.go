@@ -7,7 +7,13 @@ import (
 	"time"
 
 	"github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/service/attachments"
+	"github.com/IlianBuh/Post-service/internal/service/bookmarks"
+	"github.com/IlianBuh/Post-service/internal/service/comments"
 	"github.com/IlianBuh/Post-service/internal/service/posts"
+	"github.com/IlianBuh/Post-service/internal/service/reactions"
+	"github.com/IlianBuh/Post-service/internal/service/themes"
+	"github.com/IlianBuh/Post-service/internal/service/views"
 	grpcserver "github.com/IlianBuh/Post-service/internal/transport/grpc-server"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	"google.golang.org/grpc"
@@ -25,6 +31,12 @@ func New(
 	log *slog.Logger,
 	port int,
 	post *posts.PostService,
+	theme *themes.ThemeService,
+	attachment *attachments.AttachmentService,
+	reaction *reactions.ReactionService,
+	comment *comments.CommentService,
+	bookmark *bookmarks.BookmarkService,
+	view *views.Counter,
 	timeout time.Duration,
 ) *App {
 	recoveryOpt := []recovery.Option{
@@ -41,9 +53,12 @@ func New(
 		grpc.ChainUnaryInterceptor(
 			recovery.UnaryServerInterceptor(recoveryOpt...),
 		),
+		grpc.ChainStreamInterceptor(
+			recovery.StreamServerInterceptor(recoveryOpt...),
+		),
 	)
 
-	grpcserver.Register(grpcsrvr, post, timeout)
+	grpcserver.Register(grpcsrvr, post, theme, attachment, reaction, comment, bookmark, view, timeout)
 
 	return &App{
 		log:      log,
@@ -0,0 +1,7 @@
+package attachments
+
+type Config struct {
+	// Dir is the root directory of the local blob store
+	Dir     string `json:"dir"`
+	MaxSize int64  `json:"max-size"`
+}
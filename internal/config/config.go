@@ -6,11 +6,20 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/IlianBuh/Post-service/internal/config/attachments"
 	eventworker "github.com/IlianBuh/Post-service/internal/config/event-worker"
 	"github.com/IlianBuh/Post-service/internal/config/grpcobj"
+	"github.com/IlianBuh/Post-service/internal/config/hashtags"
 	"github.com/IlianBuh/Post-service/internal/config/kafka"
+	"github.com/IlianBuh/Post-service/internal/config/purger"
+	"github.com/IlianBuh/Post-service/internal/config/reactions"
+	"github.com/IlianBuh/Post-service/internal/config/scheduler"
 	"github.com/IlianBuh/Post-service/internal/config/storage"
+	"github.com/IlianBuh/Post-service/internal/config/themes"
+	"github.com/IlianBuh/Post-service/internal/config/thumbnailer"
+	trendworker "github.com/IlianBuh/Post-service/internal/config/trend-worker"
 	userProvider "github.com/IlianBuh/Post-service/internal/config/user-provider"
+	"github.com/IlianBuh/Post-service/internal/config/views"
 )
 
 type Config struct {
@@ -20,6 +29,15 @@ type Config struct {
 	UserProvider userProvider.Config `json:"user-provider"`
 	Kafka        kafka.Config        `json:"kafka"`
 	EventWorker  eventworker.Config  `json:"event-worker"`
+	Themes       themes.Config       `json:"themes"`
+	TrendWorker  trendworker.Config  `json:"trend-worker"`
+	Purger       purger.Config       `json:"purger"`
+	Scheduler    scheduler.Config    `json:"scheduler"`
+	Attachments  attachments.Config  `json:"attachments"`
+	Thumbnailer  thumbnailer.Config  `json:"thumbnailer"`
+	Reactions    reactions.Config    `json:"reactions"`
+	Hashtags     hashtags.Config     `json:"hashtags"`
+	Views        views.Config        `json:"views"`
 }
 
 const (
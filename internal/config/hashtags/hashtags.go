@@ -0,0 +1,12 @@
+package hashtags
+
+type Config struct {
+	// MaxThemes limits number of themes of the post, hashtags are merged
+	// only while the post has less themes. Zero disables the limit
+	MaxThemes int `json:"max-themes"`
+	// MinLength is minimum length of the inferred theme in runes
+	MinLength int `json:"min-length"`
+	// SplitWords turns '_' and '-' of hashtags into spaces,
+	// so #machine_learning becomes "machine learning"
+	SplitWords bool `json:"split-words"`
+}
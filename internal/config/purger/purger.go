@@ -0,0 +1,12 @@
+package purger
+
+import (
+	"github.com/IlianBuh/Post-service/internal/config/duration"
+)
+
+type Config struct {
+	Interval       duration.Duration `json:"interval"`
+	Retention      duration.Duration `json:"retention"`
+	IdempotencyTTL duration.Duration `json:"idempotency-ttl"`
+	BatchSize      int               `json:"batch-size"`
+}
@@ -0,0 +1,6 @@
+package reactions
+
+type Config struct {
+	// Kinds are names of reactions users can leave
+	Kinds []string `json:"kinds"`
+}
@@ -0,0 +1,10 @@
+package scheduler
+
+import (
+	"github.com/IlianBuh/Post-service/internal/config/duration"
+)
+
+type Config struct {
+	Interval  duration.Duration `json:"interval"`
+	BatchSize int               `json:"batch-size"`
+}
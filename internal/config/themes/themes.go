@@ -0,0 +1,7 @@
+package themes
+
+// Config object representation of json data
+type Config struct {
+	// Admins is a list of users allowed to merge themes and manage aliases
+	Admins []int `json:"admins"`
+}
@@ -0,0 +1,17 @@
+package thumbnailer
+
+import (
+	"github.com/IlianBuh/Post-service/internal/config/duration"
+)
+
+type Config struct {
+	Interval  duration.Duration `json:"interval"`
+	BatchSize int               `json:"batch-size"`
+	// Sizes are sides of the square boxes thumbnails fit into
+	Sizes []int `json:"sizes"`
+	// MaxPixels limits size of images which are decoded
+	MaxPixels int `json:"max-pixels"`
+	// MaxAttempts is number of failed attempts after
+	// which the image is marked as failed
+	MaxAttempts int `json:"max-attempts"`
+}
@@ -0,0 +1,9 @@
+package trendworker
+
+import (
+	"github.com/IlianBuh/Post-service/internal/config/duration"
+)
+
+type Config struct {
+	Interval duration.Duration `json:"interval"`
+}
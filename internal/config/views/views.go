@@ -0,0 +1,14 @@
+package views
+
+import (
+	"github.com/IlianBuh/Post-service/internal/config/duration"
+)
+
+type Config struct {
+	// Interval is period of flushing aggregated views to the storage
+	Interval  duration.Duration `json:"interval"`
+	BatchSize int               `json:"batch-size"`
+	// DedupWindow is period the viewer is counted once per post in.
+	// Zero counts every view
+	DedupWindow duration.Duration `json:"dedup-window"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// ImageStatus is a stage of processing of the image attachment
+type ImageStatus string
+
+const (
+	// ImageNone is a status of the attachment which is not an image
+	ImageNone ImageStatus = ""
+	// ImagePending is an image waiting for its' variants
+	ImagePending ImageStatus = "pending"
+	// ImageReady is an image with stripped metadata and variants
+	ImageReady ImageStatus = "ready"
+	// ImageFailed is an image which can't be processed
+	ImageFailed ImageStatus = "failed"
+)
+
+// Attachment is a file attached to the post. Content of the
+// file is kept in the blob store under BlobKey
+type Attachment struct {
+	Id       int
+	PostId   int
+	FileName string
+	Size     int64
+	MimeType string
+	// Sha256 is hex encoded digest of the file content
+	Sha256    string
+	BlobKey   string
+	CreatedAt time.Time
+	// ImageStatus, dimensions and variants are set only for images
+	ImageStatus ImageStatus
+	Width       int
+	Height      int
+	Variants    []ImageVariant
+}
+
+// Variant returns the variant of the image with the name.
+// Reports false if there is no such variant
+func (a Attachment) Variant(name string) (ImageVariant, bool) {
+	for _, v := range a.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+
+	return ImageVariant{}, false
+}
+
+// ImageVariant is a resized copy of the image attachment
+type ImageVariant struct {
+	Name     string
+	Width    int
+	Height   int
+	Size     int64
+	MimeType string
+	BlobKey  string
+}
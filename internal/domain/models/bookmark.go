@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// Bookmark is the post saved by the user into the named reading list
+type Bookmark struct {
+	List      string
+	Post      Post
+	CreatedAt time.Time
+}
+
+// BookmarkList is the named reading list of the user
+type BookmarkList struct {
+	Name  string
+	Count int
+}
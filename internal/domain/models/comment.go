@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Comment is a comment to the post. Top-level comment has zero
+// ParentId, reply refers to the comment it answers
+type Comment struct {
+	Id       int
+	PostId   int
+	ParentId int
+	UserId   int
+	// Content of the deleted comment is empty
+	Content   string
+	CreatedAt time.Time
+	// UpdatedAt is zero if the comment has never been edited
+	UpdatedAt time.Time
+	// DeletedAt is zero for alive comments. Deleted comment
+	// is kept without content to hold its' replies
+	DeletedAt time.Time
+	// RepliesCount is number of shown direct replies to the comment,
+	// deleted reply is shown while it has shown replies
+	RepliesCount int
+	// Replies are the first direct replies to the comment
+	Replies []Comment
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyKey identifies the request which must be done only once.
+// Fingerprint is a digest of the request payload, it detects a reused key.
+// The key expires after TTL, zero TTL means the key never expires
+type IdempotencyKey struct {
+	Key         string
+	Fingerprint string
+	TTL         time.Duration
+}
+
+func (k IdempotencyKey) IsZero() bool {
+	return k.Key == ""
+}
@@ -0,0 +1,7 @@
+package models
+
+// Mention is a user mentioned in the post by the login
+type Mention struct {
+	UserId int
+	Login  string
+}
@@ -4,11 +4,58 @@ import (
 	"time"
 )
 
+// PostStatus is a stage of post publishing
+type PostStatus string
+
+const (
+	// StatusDraft is a post visible only to its author
+	StatusDraft PostStatus = "draft"
+	// StatusScheduled is a post which is published automatically at PublishAt
+	StatusScheduled PostStatus = "scheduled"
+	// StatusPublished is a post visible to everyone
+	StatusPublished PostStatus = "published"
+)
+
+// ContentFormat is a markup language of the post content
+type ContentFormat string
+
+const (
+	// FormatPlain is a plain text shown as is
+	FormatPlain ContentFormat = "plain"
+	// FormatMarkdown is a markdown source rendered to HTML
+	FormatMarkdown ContentFormat = "markdown"
+)
+
+// RenderedContent is derived from the post content by its format
+type RenderedContent struct {
+	// HTML is sanitized HTML of the markdown content, empty for plain content
+	HTML        string
+	Excerpt     string
+	WordCount   int
+	ReadingTime time.Duration
+}
+
 type Post struct {
 	Id        int
 	UserId    int
+	Login     string
 	CreatedAt time.Time
 	Header    string
 	Content   string
 	Themes    []string
+	// ContentFormat is a format of Content, Rendered is derived from them
+	ContentFormat ContentFormat
+	Rendered      RenderedContent
+	// DeletedAt is the time the post was moved to trash, zero for alive posts
+	DeletedAt time.Time
+	Status    PostStatus
+	// PublishAt is the time the scheduled post is going to be published
+	PublishAt  time.Time
+	Visibility Visibility
+	// Version is incremented on every write of the post
+	Version int
+	// CommentsCount is number of alive comments to the post
+	CommentsCount int
+	// Views is number of views of the post flushed to the storage
+	Views int
 }
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Reaction is a reaction of the user to the post. User
+// can have only one reaction to the post
+type Reaction struct {
+	PostId    int
+	UserId    int
+	Kind      string
+	ReactedAt time.Time
+}
+
+// ReactionCount is number of reactions of the kind to the post
+type ReactionCount struct {
+	Kind  string
+	Count int
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Revision is a state of the post before one of its' updates
+type Revision struct {
+	PostId    int
+	Revision  int
+	Header    string
+	Content   string
+	Themes    []string
+	CreatedAt time.Time
+	// ContentFormat is a format of Content
+	ContentFormat ContentFormat
+}
+
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffInsert
+	DiffDelete
+)
+
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+type RevisionDiff struct {
+	Header        []DiffLine
+	Content       []DiffLine
+	AddedThemes   []string
+	RemovedThemes []string
+}
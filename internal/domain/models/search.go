@@ -0,0 +1,7 @@
+package models
+
+type SearchHit struct {
+	Post    Post
+	Rank    float32
+	Snippet string
+}
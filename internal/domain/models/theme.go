@@ -0,0 +1,14 @@
+package models
+
+type Theme struct {
+	Id         int
+	Name       string
+	PostsCount int
+}
+
+type TrendingTheme struct {
+	Theme         Theme
+	RecentPosts   int
+	BaselinePosts int
+	Score         float64
+}
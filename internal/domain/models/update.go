@@ -0,0 +1,18 @@
+package models
+
+// UpdateMask names the post fields which are replaced by an update.
+// Fields which are not named keep their old values
+type UpdateMask struct {
+	Header  bool
+	Content bool
+	Themes  bool
+}
+
+func (m UpdateMask) IsZero() bool {
+	return !m.Header && !m.Content && !m.Themes
+}
+
+// FullUpdateMask names all fields of the post
+func FullUpdateMask() UpdateMask {
+	return UpdateMask{Header: true, Content: true, Themes: true}
+}
@@ -0,0 +1,24 @@
+package models
+
+// Visibility defines who can read the post
+type Visibility string
+
+const (
+	// VisibilityPublic is a post readable and listed for everyone
+	VisibilityPublic Visibility = "public"
+	// VisibilityUnlisted is a post readable by everyone who knows its id,
+	// it is not listed for anyone except its author
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityFollowers is a post readable only by followers of its author
+	VisibilityFollowers Visibility = "followers"
+	// VisibilityPrivate is a post readable only by its author
+	VisibilityPrivate Visibility = "private"
+)
+
+// Viewer is the user who reads posts. Zero Id is an anonymous viewer.
+// Following is the list of authors followed by the viewer, it is supplied
+// by the caller
+type Viewer struct {
+	Id        int
+	Following []int
+}
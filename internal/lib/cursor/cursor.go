@@ -0,0 +1,191 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalid = errors.New("invalid cursor")
+)
+
+// Cursor is a keyset position in a list of posts ordered by
+// creation time and post id. Zero value points to the beginning of the list
+type Cursor struct {
+	CreatedAt time.Time
+	PostId    int
+}
+
+// IsZero reports whether cursor points to the beginning of the list
+func (c Cursor) IsZero() bool {
+	return c.PostId == 0 && c.CreatedAt.IsZero()
+}
+
+// Encode returns opaque token of the cursor. Zero cursor is encoded as empty string
+func Encode(c Cursor) string {
+	if c.IsZero() {
+		return ""
+	}
+
+	raw := fmt.Sprintf("%d_%d", c.CreatedAt.UnixNano(), c.PostId)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode parses token made by Encode. Empty token is decoded as zero cursor.
+// Only [ErrInvalid] can be returned as error
+func Decode(token string) (Cursor, error) {
+	if token == "" {
+		return Cursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalid
+	}
+
+	var (
+		nsec   int64
+		postId int
+	)
+	if _, err = fmt.Sscanf(string(raw), "%d_%d", &nsec, &postId); err != nil {
+		return Cursor{}, ErrInvalid
+	}
+	if postId <= 0 {
+		return Cursor{}, ErrInvalid
+	}
+
+	return Cursor{CreatedAt: time.Unix(0, nsec).UTC(), PostId: postId}, nil
+}
+
+// Rank is a keyset position in a list of posts ordered by
+// search rank and post id. Zero value points to the beginning of the list
+type Rank struct {
+	Rank   float32
+	PostId int
+}
+
+// IsZero reports whether cursor points to the beginning of the list
+func (r Rank) IsZero() bool {
+	return r.PostId == 0 && r.Rank == 0
+}
+
+// EncodeRank returns opaque token of the cursor. Zero cursor is encoded as empty string
+func EncodeRank(r Rank) string {
+	if r.IsZero() {
+		return ""
+	}
+
+	raw := fmt.Sprintf("%s_%d", strconv.FormatFloat(float64(r.Rank), 'g', -1, 32), r.PostId)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeRank parses token made by EncodeRank. Empty token is decoded as zero cursor.
+// Only [ErrInvalid] can be returned as error
+func DecodeRank(token string) (Rank, error) {
+	if token == "" {
+		return Rank{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Rank{}, ErrInvalid
+	}
+
+	rank, postId, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return Rank{}, ErrInvalid
+	}
+
+	r, err := strconv.ParseFloat(rank, 32)
+	if err != nil {
+		return Rank{}, ErrInvalid
+	}
+	id, err := strconv.Atoi(postId)
+	if err != nil || id <= 0 {
+		return Rank{}, ErrInvalid
+	}
+
+	return Rank{Rank: float32(r), PostId: id}, nil
+}
+
+// Count is a keyset position in a list of entities ordered by
+// descending counter and ascending id. Zero value points to the beginning of the list
+type Count struct {
+	Count int
+	Id    int
+}
+
+// IsZero reports whether cursor points to the beginning of the list
+func (c Count) IsZero() bool {
+	return c.Id == 0 && c.Count == 0
+}
+
+// EncodeCount returns opaque token of the cursor. Zero cursor is encoded as empty string
+func EncodeCount(c Count) string {
+	if c.IsZero() {
+		return ""
+	}
+
+	raw := fmt.Sprintf("%d_%d", c.Count, c.Id)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCount parses token made by EncodeCount. Empty token is decoded as zero cursor.
+// Only [ErrInvalid] can be returned as error
+func DecodeCount(token string) (Count, error) {
+	if token == "" {
+		return Count{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Count{}, ErrInvalid
+	}
+
+	var c Count
+	if _, err = fmt.Sscanf(string(raw), "%d_%d", &c.Count, &c.Id); err != nil {
+		return Count{}, ErrInvalid
+	}
+	if c.Id <= 0 || c.Count < 0 {
+		return Count{}, ErrInvalid
+	}
+
+	return c, nil
+}
+
+// EncodeId returns opaque token of a keyset position in a list ordered by
+// id. Zero id is encoded as empty string
+func EncodeId(id int) string {
+	if id == 0 {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+// DecodeId parses token made by EncodeId. Empty token is decoded as zero id.
+// Only [ErrInvalid] can be returned as error
+func DecodeId(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalid
+	}
+
+	id, err := strconv.Atoi(string(raw))
+	if err != nil || id <= 0 {
+		return 0, ErrInvalid
+	}
+
+	return id, nil
+}
@@ -0,0 +1,42 @@
+package cursor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	c := Cursor{
+		CreatedAt: time.Date(2025, 4, 1, 12, 30, 15, 123456000, time.UTC),
+		PostId:    42,
+	}
+
+	got, err := Decode(Encode(c))
+	require.NoError(t, err)
+	require.True(t, c.CreatedAt.Equal(got.CreatedAt))
+	require.Equal(t, c.PostId, got.PostId)
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	got, err := Decode("")
+	require.NoError(t, err)
+	require.True(t, got.IsZero())
+	require.Equal(t, "", Encode(got))
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, token := range []string{"%%%", "Zm9v", "MTBfMA"} {
+		_, err := Decode(token)
+		require.ErrorIs(t, err, ErrInvalid, token)
+	}
+}
+
+func TestEncodeDecodeRank(t *testing.T) {
+	r := Rank{Rank: 0.0607927, PostId: 7}
+
+	got, err := DecodeRank(EncodeRank(r))
+	require.NoError(t, err)
+	require.Equal(t, r, got)
+}
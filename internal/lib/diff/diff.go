@@ -0,0 +1,189 @@
+package diff
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+)
+
+// MaxLines limits number of lines of every diffed text,
+// the diff takes time proportional to product of the line counts
+const MaxLines = 5000
+
+var ErrTooLarge = errors.New("text has too many lines to diff")
+
+// Lines returns line-based diff which turns text a into text b.
+// Diff is built on the longest common subsequence of lines in linear space,
+// deleted lines go before inserted ones within a changed block. Returns
+// [ErrTooLarge] if any of the texts has more than [MaxLines] lines
+func Lines(a, b string) ([]models.DiffLine, error) {
+	x, y := split(a), split(b)
+	if len(x) > MaxLines || len(y) > MaxLines {
+		return nil, ErrTooLarge
+	}
+
+	// common prefix and suffix are equal lines, only the middle is diffed
+	pre := 0
+	for pre < len(x) && pre < len(y) && x[pre] == y[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(x)-pre && suf < len(y)-pre && x[len(x)-1-suf] == y[len(y)-1-suf] {
+		suf++
+	}
+
+	res := make([]models.DiffLine, 0, max(len(x), len(y)))
+	res = appendLines(res, models.DiffEqual, x[:pre])
+	res = lcsDiff(res, x[pre:len(x)-suf], y[pre:len(y)-suf])
+	res = appendLines(res, models.DiffEqual, x[len(x)-suf:])
+
+	return deletesFirst(res), nil
+}
+
+// Sets returns values of b missing in a and values of a missing in b
+func Sets(a, b []string) (added []string, removed []string) {
+	inA := make(map[string]struct{}, len(a))
+	for _, v := range a {
+		inA[v] = struct{}{}
+	}
+	inB := make(map[string]struct{}, len(b))
+	for _, v := range b {
+		inB[v] = struct{}{}
+	}
+
+	added, removed = make([]string, 0), make([]string, 0)
+	for _, v := range b {
+		if _, ok := inA[v]; !ok {
+			added = append(added, v)
+		}
+	}
+	for _, v := range a {
+		if _, ok := inB[v]; !ok {
+			removed = append(removed, v)
+		}
+	}
+
+	return added, removed
+}
+
+// lcsDiff appends diff of x and y to res. Hirschberg's algorithm is used:
+// x is split in half and y is split where the longest common subsequences
+// of the halves sum up to the longest one, then the halves are diffed
+// recursively. Only two rows of lengths are kept at once
+func lcsDiff(res []models.DiffLine, x, y []string) []models.DiffLine {
+	switch {
+	case len(x) == 0:
+		return appendLines(res, models.DiffInsert, y)
+	case len(y) == 0:
+		return appendLines(res, models.DiffDelete, x)
+	case len(x) == 1:
+		for k := range y {
+			if y[k] == x[0] {
+				res = appendLines(res, models.DiffInsert, y[:k])
+				res = append(res, models.DiffLine{Op: models.DiffEqual, Text: x[0]})
+				return appendLines(res, models.DiffInsert, y[k+1:])
+			}
+		}
+
+		res = append(res, models.DiffLine{Op: models.DiffDelete, Text: x[0]})
+		return appendLines(res, models.DiffInsert, y)
+	}
+
+	mid := len(x) / 2
+	head := lcsLens(x[:mid], y, false)
+	tail := lcsLens(x[mid:], y, true)
+
+	split, best := 0, -1
+	for j := range head {
+		if head[j]+tail[j] > best {
+			split, best = j, head[j]+tail[j]
+		}
+	}
+
+	res = lcsDiff(res, x[:mid], y[:split])
+	return lcsDiff(res, x[mid:], y[split:])
+}
+
+// lcsLens returns lengths of the longest common subsequences of x and every
+// prefix y[:j] of y, or of every suffix y[j:] if reversed is set
+func lcsLens(x, y []string, reversed bool) []int {
+	prev := make([]int, len(y)+1)
+	cur := make([]int, len(y)+1)
+
+	for i := range x {
+		xi := x[i]
+		if reversed {
+			xi = x[len(x)-1-i]
+		}
+
+		for j := 1; j <= len(y); j++ {
+			yj := y[j-1]
+			if reversed {
+				yj = y[len(y)-j]
+			}
+
+			if xi == yj {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	if reversed {
+		slices.Reverse(prev)
+	}
+
+	return prev
+}
+
+// deletesFirst moves deleted lines before inserted ones
+// within every block of changed lines
+func deletesFirst(lines []models.DiffLine) []models.DiffLine {
+	for start := 0; start < len(lines); {
+		if lines[start].Op == models.DiffEqual {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(lines) && lines[end].Op != models.DiffEqual {
+			end++
+		}
+		slices.SortStableFunc(lines[start:end], func(a, b models.DiffLine) int {
+			return cmp.Compare(opOrder(a.Op), opOrder(b.Op))
+		})
+		start = end
+	}
+
+	return lines
+}
+
+func opOrder(op models.DiffOp) int {
+	if op == models.DiffDelete {
+		return 0
+	}
+
+	return 1
+}
+
+func appendLines(res []models.DiffLine, op models.DiffOp, lines []string) []models.DiffLine {
+	for _, line := range lines {
+		res = append(res, models.DiffLine{Op: op, Text: line})
+	}
+
+	return res
+}
+
+// split splits text into lines. Empty text has no lines
+func split(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
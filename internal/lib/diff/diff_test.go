@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLines(t *testing.T) {
+	got, err := Lines("a\nb\nc\nd", "a\nx\nc\nd\ne")
+	require.NoError(t, err)
+
+	require.Equal(t, []models.DiffLine{
+		{Op: models.DiffEqual, Text: "a"},
+		{Op: models.DiffDelete, Text: "b"},
+		{Op: models.DiffInsert, Text: "x"},
+		{Op: models.DiffEqual, Text: "c"},
+		{Op: models.DiffEqual, Text: "d"},
+		{Op: models.DiffInsert, Text: "e"},
+	}, got)
+}
+
+func TestLinesEmpty(t *testing.T) {
+	got, err := Lines("", "")
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	got, err = Lines("", "a")
+	require.NoError(t, err)
+	require.Equal(t,
+		[]models.DiffLine{{Op: models.DiffInsert, Text: "a"}},
+		got,
+	)
+}
+
+func TestLinesDeletesFirst(t *testing.T) {
+	got, err := Lines("a\nb\nc", "x\ny\nc")
+	require.NoError(t, err)
+
+	require.Equal(t, []models.DiffLine{
+		{Op: models.DiffDelete, Text: "a"},
+		{Op: models.DiffDelete, Text: "b"},
+		{Op: models.DiffInsert, Text: "x"},
+		{Op: models.DiffInsert, Text: "y"},
+		{Op: models.DiffEqual, Text: "c"},
+	}, got)
+}
+
+func TestLinesLarge(t *testing.T) {
+	a, b := make([]string, MaxLines), make([]string, MaxLines)
+	for i := range a {
+		a[i] = fmt.Sprintf("line %d", i)
+		b[i] = a[i]
+		if i%7 == 0 {
+			b[i] = fmt.Sprintf("changed %d", i)
+		}
+	}
+
+	got, err := Lines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+	require.NoError(t, err)
+
+	var from, to []string
+	equal := 0
+	for _, line := range got {
+		if line.Op != models.DiffInsert {
+			from = append(from, line.Text)
+		}
+		if line.Op != models.DiffDelete {
+			to = append(to, line.Text)
+		}
+		if line.Op == models.DiffEqual {
+			equal++
+		}
+	}
+	require.Equal(t, a, from)
+	require.Equal(t, b, to)
+	require.Equal(t, MaxLines-(MaxLines+6)/7, equal)
+}
+
+func TestLinesTooLarge(t *testing.T) {
+	text := strings.Repeat("line\n", MaxLines)
+
+	_, err := Lines(text, "a")
+	require.ErrorIs(t, err, ErrTooLarge)
+	_, err = Lines("a", text)
+	require.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestSets(t *testing.T) {
+	added, removed := Sets([]string{"go", "tech"}, []string{"tech", "life"})
+
+	require.Equal(t, []string{"life"}, added)
+	require.Equal(t, []string{"go"}, removed)
+}
@@ -0,0 +1,99 @@
+package hashtags
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/IlianBuh/Post-service/internal/lib/normalize"
+)
+
+// Options control inferring of themes from hashtags
+type Options struct {
+	// MaxThemes limits number of themes of the post, themes are inferred
+	// only while the post has less themes. Zero disables the limit
+	MaxThemes int
+	// MinLength is minimum length of the inferred theme in runes
+	MinLength int
+	// SplitWords turns '_' and '-' of hashtags into spaces
+	SplitWords bool
+}
+
+// Parse returns hashtags of the text without '#' and duplicates keeping the
+// original order. Hashtag consists of letters, digits, '_' and '-' and has at
+// least one letter, so issue numbers like #42 are skipped. '#' inside words
+// and after '/' or '&', as in links and entities, does not start a hashtag
+func Parse(text string) []string {
+	var res []string
+	seen := make(map[string]struct{})
+
+	prev := ' '
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r != '#' || isTagRune(prev) || strings.ContainsRune("#/&", prev) {
+			prev = r
+			i += size
+			continue
+		}
+
+		j := i + size
+		hasLetter := false
+		for j < len(text) {
+			r, size := utf8.DecodeRuneInString(text[j:])
+			if !isTagRune(r) {
+				break
+			}
+			hasLetter = hasLetter || unicode.IsLetter(r)
+			j += size
+		}
+
+		tag := strings.TrimRight(text[i+size:j], "-")
+		if _, ok := seen[tag]; hasLetter && !ok {
+			seen[tag] = struct{}{}
+			res = append(res, tag)
+		}
+
+		prev, _ = utf8.DecodeLastRuneInString(text[:j])
+		i = j
+	}
+
+	return res
+}
+
+// Infer returns themes inferred from hashtags of the text which are not
+// among the themes. Themes must be normalized, inferred themes are
+// normalized as well
+func Infer(text string, themes []string, opts Options) []string {
+	seen := make(map[string]struct{}, len(themes))
+	for _, theme := range themes {
+		seen[theme] = struct{}{}
+	}
+
+	var res []string
+	for _, tag := range Parse(text) {
+		if opts.MaxThemes > 0 && len(themes)+len(res) >= opts.MaxThemes {
+			break
+		}
+
+		if opts.SplitWords {
+			tag = strings.NewReplacer("_", " ", "-", " ").Replace(tag)
+		}
+		theme := normalize.Theme(tag)
+		if utf8.RuneCountInString(theme) < opts.MinLength {
+			continue
+		}
+		if _, ok := seen[theme]; ok {
+			continue
+		}
+
+		seen[theme] = struct{}{}
+		res = append(res, theme)
+	}
+
+	return res
+}
+
+// isTagRune reports whether the rune can be a part of the hashtag
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+}
@@ -0,0 +1,36 @@
+package hashtags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string][]string{
+		"#go and #Go":            {"go", "Go"},
+		"fixed in #42, see #go-": {"go"},
+		"(#machine_learning)":    {"machine_learning"},
+		"a#b https://x.com/#top": nil,
+		"&#39; ## #":             nil,
+		"#кофе#tea":              {"кофе"},
+		"":                       nil,
+	}
+
+	for in, want := range cases {
+		require.Equal(t, want, Parse(in), in)
+	}
+}
+
+func TestInfer(t *testing.T) {
+	text := "#Go #golang #machine_learning #x #go #rust"
+
+	got := Infer(text, []string{"golang"}, Options{MinLength: 2, SplitWords: true})
+	require.Equal(t, []string{"go", "machine learning", "rust"}, got)
+
+	got = Infer(text, []string{"golang"}, Options{MaxThemes: 3})
+	require.Equal(t, []string{"go", "machine_learning"}, got)
+
+	got = Infer(text, []string{"a", "b"}, Options{MaxThemes: 2})
+	require.Empty(t, got)
+}
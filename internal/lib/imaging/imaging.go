@@ -0,0 +1,212 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	// gif decoder is used by image.Decode
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+const (
+	MimeJPEG = "image/jpeg"
+	MimePNG  = "image/png"
+	MimeGIF  = "image/gif"
+
+	jpegQuality = 85
+)
+
+var ErrMalformed = errors.New("malformed image")
+
+// Supported reports whether images of the MIME type can be processed
+func Supported(mimeType string) bool {
+	switch mimeType {
+	case MimeJPEG, MimePNG, MimeGIF:
+		return true
+	}
+
+	return false
+}
+
+// VariantMimeType returns MIME type of resized copies of the image.
+// Photos are kept in JPEG, other images in PNG to keep transparency
+func VariantMimeType(mimeType string) string {
+	if mimeType == MimeJPEG {
+		return MimeJPEG
+	}
+
+	return MimePNG
+}
+
+// Encode encodes the image in the format of the MIME type returned by [VariantMimeType]
+func Encode(w io.Writer, img image.Image, mimeType string) error {
+	if VariantMimeType(mimeType) == MimeJPEG {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	}
+
+	return png.Encode(w, img)
+}
+
+// Decode decodes the image. Images with more than maxPixels pixels
+// are not decoded, [ErrMalformed] is returned instead
+func Decode(data []byte, maxPixels int) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Join(ErrMalformed, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrMalformed
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Join(ErrMalformed, err)
+	}
+
+	return img, nil
+}
+
+// Thumbnail scales the image down to fit into the box x box square keeping
+// its' aspect ratio. Every pixel of the thumbnail is an average of the source
+// pixels it covers. Image which already fits into the box is only copied
+func Thumbnail(src image.Image, box int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	tw, th := w, h
+	if w > box || h > box {
+		if w >= h {
+			tw, th = box, max(1, h*box/w)
+		} else {
+			tw, th = max(1, w*box/h), box
+		}
+	}
+
+	// premultiplied colors are averaged, so transparent pixels don't darken edges
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	if tw == w && th == h {
+		return rgba
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := range th {
+		y0, y1 := y*h/th, max((y+1)*h/th, y*h/th+1)
+		for x := range tw {
+			x0, x1 := x*w/tw, max((x+1)*w/tw, x*w/tw+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					px := row[sx*4 : sx*4+4]
+					for c := range sum {
+						sum[c] += int(px[c])
+					}
+				}
+			}
+
+			n := (y1 - y0) * (x1 - x0)
+			off := y*dst.Stride + x*4
+			for c := range sum {
+				dst.Pix[off+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
+
+// StripMetadata removes EXIF metadata from the image without re-encoding.
+// JPEG loses its' APP1 segments and PNG its' eXIf chunks, GIF has no EXIF
+// and is returned as is
+func StripMetadata(data []byte, mimeType string) ([]byte, error) {
+	switch mimeType {
+	case MimeJPEG:
+		return stripJPEG(data)
+	case MimePNG:
+		return stripPNG(data)
+	}
+
+	return data, nil
+}
+
+// stripJPEG copies all segments before the image data except APP1
+// ones, which keep EXIF and XMP. Image data is copied as is
+func stripJPEG(data []byte) ([]byte, error) {
+	const (
+		markerSOI  = 0xD8
+		markerSOS  = 0xDA
+		markerAPP1 = 0xE1
+	)
+
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil, ErrMalformed
+	}
+
+	res := make([]byte, 0, len(data))
+	res = append(res, data[:2]...)
+
+	for i := 2; ; {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, ErrMalformed
+		}
+
+		marker := data[i+1]
+		// fill bytes may precede a marker
+		if marker == 0xFF {
+			i++
+			continue
+		}
+
+		if marker == markerSOS {
+			return append(res, data[i:]...), nil
+		}
+
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return nil, ErrMalformed
+		}
+
+		if marker != markerAPP1 {
+			res = append(res, data[i:end]...)
+		}
+		i = end
+	}
+}
+
+// stripPNG copies all chunks except eXIf ones
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, ErrMalformed
+	}
+
+	res := make([]byte, 0, len(data))
+	res = append(res, signature...)
+
+	for i := len(signature); i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrMalformed
+		}
+
+		// length, type, data and crc
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return nil, ErrMalformed
+		}
+
+		if string(data[i+4:i+8]) != "eXIf" {
+			res = append(res, data[i:end]...)
+		}
+		i = end
+	}
+
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 100, 255})
+		}
+	}
+
+	return img
+}
+
+func TestThumbnail(t *testing.T) {
+	got := Thumbnail(testImage(400, 200), 100)
+	require.Equal(t, image.Rect(0, 0, 100, 50), got.Bounds())
+
+	got = Thumbnail(testImage(30, 300), 100)
+	require.Equal(t, image.Rect(0, 0, 10, 100), got.Bounds())
+
+	// small images are not upscaled
+	got = Thumbnail(testImage(40, 20), 100)
+	require.Equal(t, image.Rect(0, 0, 40, 20), got.Bounds())
+}
+
+func TestThumbnailAverages(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{0, 0, 0, 255})
+	src.Set(1, 0, color.RGBA{200, 100, 50, 255})
+
+	got := Thumbnail(src, 1).(*image.RGBA)
+	require.Equal(t, color.RGBA{100, 50, 25, 255}, got.RGBAAt(0, 0))
+}
+
+func TestDecode(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(20, 10)))
+
+	img, err := Decode(buf.Bytes(), 200)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 20, 10), img.Bounds())
+
+	_, err = Decode(buf.Bytes(), 199)
+	require.ErrorIs(t, err, ErrMalformed)
+
+	_, err = Decode([]byte("not an image"), 200)
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestStripJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(8, 8), nil))
+	orig := buf.Bytes()
+
+	exif := append([]byte{0xFF, 0xE1, 0, 12}, []byte("Exif\x00\x00GPS!")...)
+	withExif := append(append(append([]byte{}, orig[:2]...), exif...), orig[2:]...)
+
+	got, err := StripMetadata(withExif, MimeJPEG)
+	require.NoError(t, err)
+	require.Equal(t, orig, got)
+
+	_, err = jpeg.Decode(bytes.NewReader(got))
+	require.NoError(t, err)
+
+	_, err = StripMetadata([]byte{0xFF, 0xD8, 0xFF}, MimeJPEG)
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(8, 8)))
+	orig := buf.Bytes()
+
+	// eXIf chunk is placed right after IHDR, which is 8+4+4+13+4 bytes long
+	const ihdrEnd = 8 + 25
+	chunk := pngChunk("eXIf", []byte("MM\x00*GPS!"))
+	withExif := append(append(append([]byte{}, orig[:ihdrEnd]...), chunk...), orig[ihdrEnd:]...)
+
+	got, err := StripMetadata(withExif, MimePNG)
+	require.NoError(t, err)
+	require.Equal(t, orig, got)
+
+	_, err = png.Decode(bytes.NewReader(got))
+	require.NoError(t, err)
+}
+
+func TestSupported(t *testing.T) {
+	require.True(t, Supported(MimePNG))
+	require.True(t, Supported(MimeGIF))
+	require.False(t, Supported("image/webp"))
+	require.Equal(t, MimePNG, VariantMimeType(MimeGIF))
+	require.Equal(t, MimeJPEG, VariantMimeType(MimeJPEG))
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	res := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	res = append(res, typ...)
+	res = append(res, data...)
+
+	return binary.BigEndian.AppendUint32(res, crc32.ChecksumIEEE(res[4:]))
+}
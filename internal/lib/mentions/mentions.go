@@ -0,0 +1,55 @@
+package mentions
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Parse returns logins mentioned in the texts as @login without duplicates
+// keeping the original order. Login consists of letters, digits, '_', '.' and
+// '-'. Trailing dots and hyphens are treated as punctuation, '@' inside words,
+// as in emails, and after '/', as in links, does not start a mention
+func Parse(texts ...string) []string {
+	var res []string
+	seen := make(map[string]struct{})
+
+	for _, text := range texts {
+		prev := ' '
+		for i := 0; i < len(text); {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			if r != '@' || isLoginRune(prev) || prev == '@' || prev == '/' {
+				prev = r
+				i += size
+				continue
+			}
+
+			j := i + size
+			for j < len(text) {
+				r, size := utf8.DecodeRuneInString(text[j:])
+				if !isLoginRune(r) {
+					break
+				}
+				j += size
+			}
+
+			login := strings.TrimRight(text[i+size:j], ".-")
+			if login != "" {
+				if _, ok := seen[login]; !ok {
+					seen[login] = struct{}{}
+					res = append(res, login)
+				}
+			}
+
+			prev, _ = utf8.DecodeLastRuneInString(text[:j])
+			i = j
+		}
+	}
+
+	return res
+}
+
+// isLoginRune reports whether the rune can be a part of the login
+func isLoginRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
@@ -0,0 +1,31 @@
+package mentions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string][]string{
+		"hi @bob":                    {"bob"},
+		"@alice, @bob and @alice":    {"alice", "bob"},
+		"thanks @john.doe.":          {"john.doe"},
+		"(@ann_1) @кира-":            {"ann_1", "кира"},
+		"mail me at bob@example.com": nil,
+		"@@bob @ alone":              nil,
+		"@bob@alice":                 {"bob"},
+		"see https://x.com/@bob":     nil,
+		"":                           nil,
+	}
+
+	for in, want := range cases {
+		require.Equal(t, want, Parse(in), in)
+	}
+}
+
+func TestParseTexts(t *testing.T) {
+	got := Parse("Ask @bob", "@alice knows, @bob too")
+
+	require.Equal(t, []string{"bob", "alice"}, got)
+}
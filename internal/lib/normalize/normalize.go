@@ -0,0 +1,40 @@
+package normalize
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Theme returns canonical form of the theme name: surrounding whitespace is
+// trimmed, inner whitespace is collapsed into single spaces, letters are
+// case folded and the result is composed to NFC
+func Theme(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+
+	// caser keeps state, so it must not be shared between goroutines
+	return norm.NFC.String(cases.Fold().String(name))
+}
+
+// Themes returns canonical forms of theme names without empty and
+// duplicate values keeping the original order
+func Themes(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	res := make([]string, 0, len(names))
+
+	for _, name := range names {
+		name = Theme(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+
+		seen[name] = struct{}{}
+		res = append(res, name)
+	}
+
+	return res
+}
@@ -0,0 +1,29 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTheme(t *testing.T) {
+	cases := map[string]string{
+		"Go":                  "go",
+		"  go ":               "go",
+		"Machine \t Learning": "machine learning",
+		"STRASSE":             "strasse",
+		"Straße":              "strasse",
+		"Cafe\u0301":          "caf\u00e9",
+		"":                    "",
+	}
+
+	for in, want := range cases {
+		require.Equal(t, want, Theme(in), in)
+	}
+}
+
+func TestThemes(t *testing.T) {
+	got := Themes([]string{"Go", "go ", " ", "golang", "GO"})
+
+	require.Equal(t, []string{"go", "golang"}, got)
+}
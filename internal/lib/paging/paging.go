@@ -0,0 +1,32 @@
+package paging
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Limit returns page size bounded by [1, MaxPageSize].
+// Non-positive size is replaced with DefaultPageSize
+func Limit(size int) int {
+	switch {
+	case size <= 0:
+		return DefaultPageSize
+	case size > MaxPageSize:
+		return MaxPageSize
+	}
+
+	return size
+}
+
+// Cut cuts items that were fetched with limit+1 size to the limit and returns
+// token of the next page made of the last item. Token is empty if there is no
+// next page
+func Cut[T any](items []T, limit int, token func(last T) string) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+
+	items = items[:limit]
+
+	return items, token(items[limit-1])
+}
@@ -0,0 +1,42 @@
+package paging
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want int
+	}{
+		{name: "zero", size: 0, want: DefaultPageSize},
+		{name: "negative", size: -5, want: DefaultPageSize},
+		{name: "in bounds", size: 7, want: 7},
+		{name: "max", size: MaxPageSize, want: MaxPageSize},
+		{name: "too big", size: MaxPageSize + 1, want: MaxPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Limit(tt.size))
+		})
+	}
+}
+
+func TestCut(t *testing.T) {
+	token := func(last int) string {
+		return strconv.Itoa(last)
+	}
+
+	items, next := Cut([]int{1, 2, 3}, 3, token)
+	require.Equal(t, []int{1, 2, 3}, items)
+	require.Empty(t, next)
+
+	items, next = Cut([]int{1, 2, 3, 4}, 3, token)
+	require.Equal(t, []int{1, 2, 3}, items)
+	require.Equal(t, "3", next)
+}
@@ -0,0 +1,58 @@
+package periodic
+
+import (
+	"sync"
+	"time"
+)
+
+// Runner runs the task until it is stopped. Stop can be called
+// even if Start has never run, and can be called more than once
+type Runner struct {
+	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func New(interval time.Duration) *Runner {
+	return &Runner{
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start runs the task in a new goroutine on every tick of the interval.
+// The first run is after the first tick, runs of the task never overlap
+func (r *Runner) Start(task func()) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+
+			task()
+		}
+	}()
+}
+
+// Done returns the channel which is closed when the runner is stopped.
+// Long tasks check it to return between their steps
+func (r *Runner) Done() <-chan struct{} {
+	return r.stop
+}
+
+// Stop stops the runner and waits until the running task is done
+func (r *Runner) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	r.wg.Wait()
+}
@@ -0,0 +1,50 @@
+package periodic
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunnerRunsTask(t *testing.T) {
+	var runs atomic.Int32
+	r := New(time.Millisecond)
+	r.Start(func() {
+		runs.Add(1)
+	})
+
+	require.Eventually(t, func() bool {
+		return runs.Load() >= 2
+	}, time.Second, time.Millisecond)
+
+	r.Stop()
+	stopped := runs.Load()
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, stopped, runs.Load())
+}
+
+func TestRunnerStopWithoutStart(t *testing.T) {
+	r := New(time.Millisecond)
+
+	r.Stop()
+	r.Stop()
+}
+
+func TestRunnerDone(t *testing.T) {
+	r := New(time.Hour)
+
+	select {
+	case <-r.Done():
+		t.Fatal("runner is done before stop")
+	default:
+	}
+
+	r.Stop()
+	select {
+	case <-r.Done():
+	default:
+		t.Fatal("runner is not done after stop")
+	}
+}
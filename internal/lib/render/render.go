@@ -0,0 +1,91 @@
+package render
+
+import (
+	"bytes"
+	"html"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+const (
+	// ExcerptLen is the maximum length of the excerpt in runes
+	ExcerptLen = 200
+	// WordsPerMinute is the reading speed used to estimate reading time
+	WordsPerMinute = 200
+)
+
+var (
+	md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	// policies are safe for concurrent use once built
+	ugcPolicy   = bluemonday.UGCPolicy()
+	stripPolicy = bluemonday.StrictPolicy()
+)
+
+// Content renders the content by its format. HTML is rendered only for
+// markdown content and is sanitized, so it can be embedded into pages and
+// emails as is. Excerpt, word count and reading time are counted over the
+// plain text of the content
+func Content(format models.ContentFormat, src string) (models.RenderedContent, error) {
+	text := src
+	var res models.RenderedContent
+
+	if format == models.FormatMarkdown {
+		var buf bytes.Buffer
+		if err := md.Convert([]byte(src), &buf); err != nil {
+			return models.RenderedContent{}, err
+		}
+
+		res.HTML = ugcPolicy.Sanitize(buf.String())
+		text = PlainText(res.HTML)
+	}
+
+	words := strings.Fields(text)
+	res.Excerpt = Excerpt(strings.Join(words, " "), ExcerptLen)
+	res.WordCount = len(words)
+	res.ReadingTime = ReadingTime(res.WordCount)
+
+	return res, nil
+}
+
+// PlainText strips all tags of the html and unescapes its entities
+func PlainText(htmlSrc string) string {
+	// tags are replaced by spaces, so text of adjacent blocks is not glued
+	text := stripPolicy.Sanitize(strings.ReplaceAll(htmlSrc, "<", " <"))
+
+	return html.UnescapeString(text)
+}
+
+// Excerpt returns at most maxLen runes of the text. The text is cut on the
+// word boundary and ellipsis is appended if the text is cut
+func Excerpt(text string, maxLen int) string {
+	if utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:maxLen])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+// ReadingTime estimates time of reading the words rounded up to minutes
+func ReadingTime(words int) time.Duration {
+	if words == 0 {
+		return 0
+	}
+
+	minutes := math.Ceil(float64(words) / WordsPerMinute)
+
+	return time.Duration(minutes) * time.Minute
+}
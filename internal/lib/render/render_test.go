@@ -0,0 +1,49 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentMarkdown(t *testing.T) {
+	src := "# Title\n\nSome **bold** text.\n\n<script>alert(1)</script>\n\n[link](javascript:alert(1))"
+
+	got, err := Content(models.FormatMarkdown, src)
+	require.NoError(t, err)
+
+	require.Contains(t, got.HTML, "<h1")
+	require.Contains(t, got.HTML, "<strong>bold</strong>")
+	require.NotContains(t, got.HTML, "<script")
+	require.NotContains(t, got.HTML, "javascript:")
+	require.Equal(t, "Title Some bold text. link", got.Excerpt)
+	require.Equal(t, 5, got.WordCount)
+	require.Equal(t, time.Minute, got.ReadingTime)
+}
+
+func TestContentPlain(t *testing.T) {
+	src := "plain   **text**\n with <b>tags</b>"
+
+	got, err := Content(models.FormatPlain, src)
+	require.NoError(t, err)
+
+	require.Empty(t, got.HTML)
+	require.Equal(t, "plain **text** with <b>tags</b>", got.Excerpt)
+	require.Equal(t, 4, got.WordCount)
+}
+
+func TestExcerpt(t *testing.T) {
+	require.Equal(t, "short", Excerpt("short", 10))
+	require.Equal(t, "one two…", Excerpt("one two three", 10))
+	require.Equal(t, "абвгд…", Excerpt("абвгдеёжзи", 5))
+}
+
+func TestReadingTime(t *testing.T) {
+	require.Equal(t, time.Duration(0), ReadingTime(0))
+	require.Equal(t, time.Minute, ReadingTime(1))
+	require.Equal(t, 3*time.Minute, ReadingTime(2*WordsPerMinute+1))
+	require.Equal(t, 5*time.Minute, ReadingTime(len(strings.Fields(strings.Repeat("w ", 1000)))))
+}
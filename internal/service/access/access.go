@@ -0,0 +1,78 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+// Errors of the checks are reused by the services, so the services
+// return them as their own errors
+var (
+	ErrInternal     = errors.New("internal error")
+	ErrNotFound     = errors.New("not found")
+	ErrUserNotFound = errors.New("user does not exist")
+)
+
+type UserProvider interface {
+	Exists(ctx context.Context, uuid int) (isExists bool, err error)
+}
+
+type PostProvider interface {
+	Post(ctx context.Context, viewer models.Viewer, postId int) (models.Post, error)
+}
+
+// CheckUserExisting checks if user exists.
+// Only [ErrInternal] or [ErrUserNotFound] can be returned as error
+func CheckUserExisting(
+	ctx context.Context,
+	log *slog.Logger,
+	usrPrvdr UserProvider,
+	userId int,
+) error {
+	const op = "access.CheckUserExisting"
+	log = log.With(slog.String("op", op))
+
+	ok, err := usrPrvdr.Exists(ctx, userId)
+	if err != nil {
+		log.Error("failed to check users' existing", sl.Err(err))
+		return errs.Fail(op, ErrInternal)
+	}
+	if !ok {
+		log.Warn("user does not exist", slog.Int("uuid", userId))
+		return errs.Fail(op, ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// CheckPostReadable checks if the viewer can read the post.
+// Only [ErrInternal] or [ErrNotFound] can be returned as error
+func CheckPostReadable(
+	ctx context.Context,
+	log *slog.Logger,
+	prvdr PostProvider,
+	viewer models.Viewer,
+	postId int,
+) error {
+	const op = "access.CheckPostReadable"
+	log = log.With(slog.String("op", op))
+
+	_, err := prvdr.Post(ctx, viewer, postId)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("post is not found", slog.Int("post-id", postId), sl.Err(err))
+			return errs.Fail(op, ErrNotFound)
+		}
+
+		log.Error("failed to get post", sl.Err(err))
+		return errs.Fail(op, ErrInternal)
+	}
+
+	return nil
+}
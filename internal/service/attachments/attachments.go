@@ -0,0 +1,357 @@
+package attachments
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/imaging"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	extraresources "github.com/IlianBuh/Post-service/internal/service/attachments/interfaces/extra-resources"
+	"github.com/IlianBuh/Post-service/internal/service/attachments/interfaces/repository"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+// sniffLen is number of leading bytes used to detect MIME type of the file
+const sniffLen = 512
+
+type AttachmentService struct {
+	log     *slog.Logger
+	svr     repository.Saver
+	prvdr   repository.Provider
+	blobs   extraresources.BlobStore
+	maxSize int64
+	timeout time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	svr repository.Saver,
+	prvdr repository.Provider,
+	blobs extraresources.BlobStore,
+	maxSize int64,
+	timeout time.Duration,
+) *AttachmentService {
+	return &AttachmentService{
+		log:     log,
+		svr:     svr,
+		prvdr:   prvdr,
+		blobs:   blobs,
+		maxSize: maxSize,
+		timeout: timeout,
+	}
+}
+
+// Upload reads the file from r, puts it into the blob store and attaches it
+// to the post. Only creator of the post can attach files. MIME type is
+// detected by the content, or by the extension of the file name if the
+// content is not recognized. Images are left pending until their
+// metadata is stripped and thumbnails are generated.
+// Only [ErrInternal], [ErrNotFound], [ErrNotCreator], [ErrFileExists] or
+// [ErrFileTooLarge] can be returned as error
+func (a *AttachmentService) Upload(
+	ctx context.Context,
+	postId int,
+	userId int,
+	fileName string,
+	r io.Reader,
+) (models.Attachment, error) {
+	const op = "attachment-service.Upload"
+	log := a.log.With(slog.String("op", op))
+	log.Info(
+		"starting uploading file",
+		slog.Int("post-id", postId),
+		slog.Int("user-id", userId),
+		slog.String("file-name", fileName),
+	)
+	defer log.Info("uploading file ended")
+
+	var err error
+	sendErr := func(err error) (models.Attachment, error) {
+		return models.Attachment{}, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to upload - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, a.timeout)
+	defer cncl()
+
+	// the file is not read if the user can't attach it,
+	// the check is repeated when the metadata is saved
+	post, err := a.prvdr.Post(ctx, models.Viewer{Id: userId}, postId)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("post is not found", slog.Int("post-id", postId), sl.Err(err))
+			return sendErr(ErrNotFound)
+		}
+
+		log.Error("failed to get post", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+	if post.UserId != userId {
+		log.Warn(
+			"user is not creator of the post",
+			slog.Int("post-id", postId),
+			slog.Int("user-id", userId),
+		)
+		return sendErr(ErrNotCreator)
+	}
+
+	key, err := blobKey(postId)
+	if err != nil {
+		log.Error("failed to generate blob key", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	// one extra byte is read to detect too large file
+	br := bufio.NewReaderSize(io.LimitReader(r, a.maxSize+1), sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Error("failed to read file", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+	mimeType := detectMimeType(fileName, head)
+
+	hash := sha256.New()
+	size, err := a.blobs.Put(ctx, key, io.TeeReader(br, hash))
+	if err != nil {
+		log.Error("failed to put file into blob store", sl.Err(err))
+		a.deleteBlob(key)
+		return sendErr(ErrInternal)
+	}
+	if size > a.maxSize {
+		log.Warn("file is too large", slog.Int64("max-size", a.maxSize))
+		a.deleteBlob(key)
+		return sendErr(ErrFileTooLarge)
+	}
+
+	imageStatus := models.ImageNone
+	if imaging.Supported(mimeType) {
+		imageStatus = models.ImagePending
+	}
+
+	att, err := a.svr.SaveAttachment(ctx, userId, models.Attachment{
+		PostId:      postId,
+		FileName:    fileName,
+		Size:        size,
+		MimeType:    mimeType,
+		Sha256:      hex.EncodeToString(hash.Sum(nil)),
+		BlobKey:     key,
+		ImageStatus: imageStatus,
+	})
+	if err != nil {
+		a.deleteBlob(key)
+
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			log.Warn("post is not found", slog.Int("post-id", postId), sl.Err(err))
+			return sendErr(ErrNotFound)
+		case errors.Is(err, storage.ErrNotCreator):
+			log.Warn(
+				"user is not creator of the post",
+				slog.Int("post-id", postId),
+				slog.Int("user-id", userId),
+				sl.Err(err),
+			)
+			return sendErr(ErrNotCreator)
+		case errors.Is(err, storage.ErrFileExists):
+			log.Warn("file already exists", slog.String("file-name", fileName), sl.Err(err))
+			return sendErr(ErrFileExists)
+		}
+
+		log.Error("failed to save attachment", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return att, nil
+}
+
+// Get returns metadata of the file attached to the post with status and
+// variants of the image. The viewer must be able to read the post.
+// Only [ErrInternal] or [ErrNotFound] can be returned as error
+func (a *AttachmentService) Get(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	fileName string,
+) (models.Attachment, error) {
+	const op = "attachment-service.Get"
+	log := a.log.With(slog.String("op", op))
+	log.Info(
+		"starting getting file",
+		slog.Int("viewer-id", viewer.Id),
+		slog.Int("post-id", postId),
+		slog.String("file-name", fileName),
+	)
+	defer log.Info("getting file ended")
+
+	var err error
+	sendErr := func(err error) (models.Attachment, error) {
+		return models.Attachment{}, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to get - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, a.timeout)
+	defer cncl()
+
+	att, err := a.attachment(ctx, log, viewer, postId, fileName)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	return att, nil
+}
+
+// Download returns metadata of the file attached to the post and reader of
+// its content. Non-empty variant names the resized copy of the image to read
+// instead of the original. The viewer must be able to read the post. Returned
+// reader must be closed.
+// Only [ErrInternal] or [ErrNotFound] can be returned as error
+func (a *AttachmentService) Download(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	fileName string,
+	variant string,
+) (models.Attachment, io.ReadCloser, error) {
+	const op = "attachment-service.Download"
+	log := a.log.With(slog.String("op", op))
+	log.Info(
+		"starting downloading file",
+		slog.Int("viewer-id", viewer.Id),
+		slog.Int("post-id", postId),
+		slog.String("file-name", fileName),
+		slog.String("variant", variant),
+	)
+	defer log.Info("downloading file ended")
+
+	var err error
+	sendErr := func(err error) (models.Attachment, io.ReadCloser, error) {
+		return models.Attachment{}, nil, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to download - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	// content is read by the caller after return,
+	// so the timeout bounds only metadata requests
+	tctx, cncl := context.WithTimeout(ctx, a.timeout)
+	defer cncl()
+
+	att, err := a.attachment(tctx, log, viewer, postId, fileName)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	key := att.BlobKey
+	if variant != "" {
+		v, ok := att.Variant(variant)
+		if !ok {
+			log.Warn("variant is not found", slog.String("variant", variant))
+			return sendErr(ErrNotFound)
+		}
+
+		key = v.BlobKey
+	}
+
+	content, err := a.blobs.Get(ctx, key)
+	if err != nil {
+		log.Error("failed to get file from blob store", slog.String("key", key), sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return att, content, nil
+}
+
+// attachment returns metadata of the file if the viewer can read the post.
+// Only [ErrInternal] or [ErrNotFound] can be returned as error
+func (a *AttachmentService) attachment(
+	ctx context.Context,
+	log *slog.Logger,
+	viewer models.Viewer,
+	postId int,
+	fileName string,
+) (models.Attachment, error) {
+	_, err := a.prvdr.Post(ctx, viewer, postId)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("post is not found", slog.Int("post-id", postId), sl.Err(err))
+			return models.Attachment{}, ErrNotFound
+		}
+
+		log.Error("failed to get post", sl.Err(err))
+		return models.Attachment{}, ErrInternal
+	}
+
+	att, err := a.prvdr.Attachment(ctx, postId, fileName)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("file is not found", slog.String("file-name", fileName), sl.Err(err))
+			return models.Attachment{}, ErrNotFound
+		}
+
+		log.Error("failed to get attachment", sl.Err(err))
+		return models.Attachment{}, ErrInternal
+	}
+
+	return att, nil
+}
+
+// deleteBlob deletes the blob which is not attached to any post.
+// It is called on failures, so the request context may be done
+func (a *AttachmentService) deleteBlob(key string) {
+	ctx, cncl := context.WithTimeout(context.Background(), a.timeout)
+	defer cncl()
+
+	if err := a.blobs.Delete(ctx, key); err != nil {
+		a.log.Error("failed to delete blob", slog.String("key", key), sl.Err(err))
+	}
+}
+
+// blobKey generates unique key of the file attached to the post
+func blobKey(postId int) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("posts/%d/%s", postId, hex.EncodeToString(buf)), nil
+}
+
+// detectMimeType detects MIME type by the leading bytes of the file,
+// extension of the file name is used if the content is not recognized
+func detectMimeType(fileName string, head []byte) string {
+	const unknown = "application/octet-stream"
+
+	mimeType := http.DetectContentType(head)
+	if mimeType != unknown {
+		return mimeType
+	}
+
+	if byExt := mime.TypeByExtension(filepath.Ext(fileName)); byExt != "" {
+		return byExt
+	}
+
+	return unknown
+}
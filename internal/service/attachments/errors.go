@@ -0,0 +1,13 @@
+package attachments
+
+import (
+	"errors"
+)
+
+var (
+	ErrInternal     = errors.New("internal error")
+	ErrNotFound     = errors.New("not found")
+	ErrNotCreator   = errors.New("user is not creator")
+	ErrFileExists   = errors.New("file already exists")
+	ErrFileTooLarge = errors.New("file is too large")
+)
@@ -0,0 +1,18 @@
+package extraresources
+
+import (
+	"context"
+	"io"
+)
+
+type BlobStore interface {
+	// Put writes content of the reader under the key.
+	// Return values: number of written bytes, error
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+
+	// Get opens the blob with the key, the reader must be closed
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete deletes the blob with the key
+	Delete(ctx context.Context, key string) error
+}
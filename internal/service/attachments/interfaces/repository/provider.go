@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+)
+
+type Provider interface {
+	// Post returns the post if the viewer can read it.
+	// Return values: post, error
+	Post(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+	) (models.Post, error)
+
+	// Attachment returns metadata of the file attached to the post
+	// with variants of the image. Return values: attachment, error
+	Attachment(
+		ctx context.Context,
+		postId int,
+		fileName string,
+	) (models.Attachment, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+)
+
+type Saver interface {
+	// SaveAttachment saves metadata of the file if the user is creator
+	// of the post. Return values: attachment with creation time, error
+	SaveAttachment(
+		ctx context.Context,
+		userId int,
+		att models.Attachment,
+	) (models.Attachment, error)
+}
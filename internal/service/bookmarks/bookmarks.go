@@ -0,0 +1,240 @@
+package bookmarks
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/paging"
+	"github.com/IlianBuh/Post-service/internal/service/access"
+	extraresources "github.com/IlianBuh/Post-service/internal/service/bookmarks/interfaces/extra-resources"
+	"github.com/IlianBuh/Post-service/internal/service/bookmarks/interfaces/repository"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+const (
+	// DefaultList is a reading list used when the list is not named
+	DefaultList = "default"
+)
+
+type BookmarkService struct {
+	log      *slog.Logger
+	bkmrkr   repository.Bookmarker
+	prvdr    repository.Provider
+	usrPrvdr extraresources.UserProvider
+	timeout  time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	bkmrkr repository.Bookmarker,
+	prvdr repository.Provider,
+	usrPrvdr extraresources.UserProvider,
+	timeout time.Duration,
+) *BookmarkService {
+	return &BookmarkService{
+		log:      log,
+		bkmrkr:   bkmrkr,
+		prvdr:    prvdr,
+		usrPrvdr: usrPrvdr,
+		timeout:  timeout,
+	}
+}
+
+// Add saves the post into the reading list of the viewer, empty list means
+// [DefaultList]. The viewer must exist and be able to read the post.
+// Bookmarking the post twice is not an error.
+// Only [ErrInternal], [ErrNotFound] or [ErrUserNotFound] can be returned
+// as error
+func (b *BookmarkService) Add(
+	ctx context.Context,
+	viewer models.Viewer,
+	list string,
+	postId int,
+) error {
+	const op = "bookmark-service.Add"
+	log := b.log.With(slog.String("op", op))
+	log.Info(
+		"starting adding bookmark",
+		slog.Int("user-id", viewer.Id),
+		slog.String("list", list),
+		slog.Int("post-id", postId),
+	)
+	defer log.Info("adding bookmark ended")
+
+	var err error
+	sendErr := func(err error) error {
+		return errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to add - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, b.timeout)
+	defer cncl()
+
+	if err = access.CheckUserExisting(ctx, log, b.usrPrvdr, viewer.Id); err != nil {
+		return sendErr(err)
+	}
+
+	if err = access.CheckPostReadable(ctx, log, b.prvdr, viewer, postId); err != nil {
+		return sendErr(err)
+	}
+
+	err = b.bkmrkr.AddBookmark(ctx, viewer.Id, listName(list), postId)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("post is not found", slog.Int("post-id", postId), sl.Err(err))
+			return sendErr(ErrNotFound)
+		}
+
+		log.Error("failed to add bookmark", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return nil
+}
+
+// Remove removes the post from the reading list of the user, empty list
+// means [DefaultList]. Removing missing bookmark is not an error.
+// Only [ErrInternal] can be returned as error
+func (b *BookmarkService) Remove(
+	ctx context.Context,
+	userId int,
+	list string,
+	postId int,
+) error {
+	const op = "bookmark-service.Remove"
+	log := b.log.With(slog.String("op", op))
+	log.Info(
+		"starting removing bookmark",
+		slog.Int("user-id", userId),
+		slog.String("list", list),
+		slog.Int("post-id", postId),
+	)
+	defer log.Info("removing bookmark ended")
+
+	var err error
+	sendErr := func(err error) error {
+		return errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to remove - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, b.timeout)
+	defer cncl()
+
+	if err = b.bkmrkr.RemoveBookmark(ctx, userId, listName(list), postId); err != nil {
+		log.Error("failed to remove bookmark", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return nil
+}
+
+// List returns page of bookmarks of the reading list of the viewer, most
+// recently added first, and token of the next page. Empty list means
+// [DefaultList]. Posts the viewer can't read anymore are skipped.
+// Only [ErrInternal] or [ErrInvalidToken] can be returned as error
+func (b *BookmarkService) List(
+	ctx context.Context,
+	viewer models.Viewer,
+	list string,
+	pageToken string,
+	pageSize int,
+) ([]models.Bookmark, string, error) {
+	const op = "bookmark-service.List"
+	log := b.log.With(slog.String("op", op))
+	log.Info(
+		"starting listing bookmarks",
+		slog.Int("user-id", viewer.Id),
+		slog.String("list", list),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("listing bookmarks ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Bookmark, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	after, err := cursor.Decode(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, b.timeout)
+	defer cncl()
+
+	limit := paging.Limit(pageSize)
+	bookmarks, err := b.prvdr.Bookmarks(ctx, viewer, listName(list), after, limit+1)
+	if err != nil {
+		log.Error("failed to list bookmarks", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	bookmarks, nextToken := paging.Cut(bookmarks, limit, func(last models.Bookmark) string {
+		return cursor.Encode(cursor.Cursor{CreatedAt: last.CreatedAt, PostId: last.Post.Id})
+	})
+
+	return bookmarks, nextToken, nil
+}
+
+// Lists returns reading lists of the user with number of bookmarks in them.
+// Only [ErrInternal] can be returned as error
+func (b *BookmarkService) Lists(
+	ctx context.Context,
+	userId int,
+) ([]models.BookmarkList, error) {
+	const op = "bookmark-service.Lists"
+	log := b.log.With(slog.String("op", op))
+	log.Info("starting listing reading lists", slog.Int("user-id", userId))
+	defer log.Info("listing reading lists ended")
+
+	var err error
+	sendErr := func(err error) ([]models.BookmarkList, error) {
+		return nil, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, b.timeout)
+	defer cncl()
+
+	lists, err := b.prvdr.BookmarkLists(ctx, userId)
+	if err != nil {
+		log.Error("failed to list reading lists", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return lists, nil
+}
+
+// listName replaces empty name of the list with [DefaultList]
+func listName(list string) string {
+	if list == "" {
+		return DefaultList
+	}
+
+	return list
+}
@@ -0,0 +1,14 @@
+package bookmarks
+
+import (
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/service/access"
+)
+
+var (
+	ErrInternal     = access.ErrInternal
+	ErrNotFound     = access.ErrNotFound
+	ErrUserNotFound = access.ErrUserNotFound
+	ErrInvalidToken = errors.New("invalid page token")
+)
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+)
+
+type Bookmarker interface {
+	// AddBookmark saves the post into the reading list of the user.
+	// Return values: error
+	AddBookmark(
+		ctx context.Context,
+		userId int,
+		list string,
+		postId int,
+	) error
+
+	// RemoveBookmark removes the post from the reading list of the user.
+	// Return values: error
+	RemoveBookmark(
+		ctx context.Context,
+		userId int,
+		list string,
+		postId int,
+	) error
+}
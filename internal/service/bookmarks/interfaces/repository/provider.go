@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+)
+
+type Provider interface {
+	// Post returns the post if the viewer can read it.
+	// Return values: post, error
+	Post(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+	) (models.Post, error)
+
+	// Bookmarks returns at most limit bookmarks of the reading list of the
+	// viewer after the cursor, newest first. Return values: bookmarks, error
+	Bookmarks(
+		ctx context.Context,
+		viewer models.Viewer,
+		list string,
+		after cursor.Cursor,
+		limit int,
+	) ([]models.Bookmark, error)
+
+	// BookmarkLists returns reading lists of the user.
+	// Return values: lists, error
+	BookmarkLists(
+		ctx context.Context,
+		userId int,
+	) ([]models.BookmarkList, error)
+}
@@ -0,0 +1,309 @@
+package comments
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/paging"
+	"github.com/IlianBuh/Post-service/internal/service/access"
+	extraresources "github.com/IlianBuh/Post-service/internal/service/comments/interfaces/extra-resources"
+	"github.com/IlianBuh/Post-service/internal/service/comments/interfaces/repository"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+const (
+	// previewReplies is number of first replies returned with every listed comment
+	previewReplies = 3
+)
+
+type CommentService struct {
+	log      *slog.Logger
+	svr      repository.Saver
+	edtr     repository.Editor
+	prvdr    repository.Provider
+	usrPrvdr extraresources.UserProvider
+	timeout  time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	svr repository.Saver,
+	edtr repository.Editor,
+	prvdr repository.Provider,
+	usrPrvdr extraresources.UserProvider,
+	timeout time.Duration,
+) *CommentService {
+	return &CommentService{
+		log:      log,
+		svr:      svr,
+		edtr:     edtr,
+		prvdr:    prvdr,
+		usrPrvdr: usrPrvdr,
+		timeout:  timeout,
+	}
+}
+
+// Create comments the published post on behalf of the viewer. Non-zero
+// parentId makes the comment a reply to the alive comment of the same post.
+// The viewer must exist and be able to read the post.
+// Only [ErrInternal], [ErrNotFound], [ErrUserNotFound] or [ErrParentNotFound]
+// can be returned as error
+func (c *CommentService) Create(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	parentId int,
+	content string,
+) (models.Comment, error) {
+	const op = "comment-service.Create"
+	log := c.log.With(slog.String("op", op))
+	log.Info(
+		"starting creating comment",
+		slog.Int("user-id", viewer.Id),
+		slog.Int("post-id", postId),
+		slog.Int("parent-id", parentId),
+	)
+	defer log.Info("creating comment ended")
+
+	var err error
+	sendErr := func(err error) (models.Comment, error) {
+		return models.Comment{}, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to create - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, c.timeout)
+	defer cncl()
+
+	if err = access.CheckUserExisting(ctx, log, c.usrPrvdr, viewer.Id); err != nil {
+		return sendErr(err)
+	}
+
+	if err = access.CheckPostReadable(ctx, log, c.prvdr, viewer, postId); err != nil {
+		return sendErr(err)
+	}
+
+	comment, err := c.svr.SaveComment(ctx, models.Comment{
+		PostId:   postId,
+		ParentId: parentId,
+		UserId:   viewer.Id,
+		Content:  content,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			log.Warn("post is not published", slog.Int("post-id", postId), sl.Err(err))
+			return sendErr(ErrNotFound)
+		case errors.Is(err, storage.ErrNoParent):
+			log.Warn("parent comment is not found", slog.Int("parent-id", parentId), sl.Err(err))
+			return sendErr(ErrParentNotFound)
+		}
+
+		log.Error("failed to save comment", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return comment, nil
+}
+
+// Edit replaces content of the comment. Only creator of the comment can edit it.
+// Only [ErrInternal], [ErrNotFound] or [ErrNotCreator] can be returned as error
+func (c *CommentService) Edit(
+	ctx context.Context,
+	commentId int,
+	userId int,
+	content string,
+) (models.Comment, error) {
+	const op = "comment-service.Edit"
+	log := c.log.With(slog.String("op", op))
+	log.Info(
+		"starting editing comment",
+		slog.Int("comment-id", commentId),
+		slog.Int("user-id", userId),
+	)
+	defer log.Info("editing comment ended")
+
+	var err error
+	sendErr := func(err error) (models.Comment, error) {
+		return models.Comment{}, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to edit - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, c.timeout)
+	defer cncl()
+
+	comment, err := c.edtr.EditComment(ctx, commentId, userId, content)
+	if err != nil {
+		return sendErr(c.editErr(log, err, commentId, userId))
+	}
+
+	return comment, nil
+}
+
+// Delete deletes the comment. Only creator of the comment can delete it,
+// replies to the deleted comment are kept.
+// Only [ErrInternal], [ErrNotFound] or [ErrNotCreator] can be returned as error
+func (c *CommentService) Delete(
+	ctx context.Context,
+	commentId int,
+	userId int,
+) error {
+	const op = "comment-service.Delete"
+	log := c.log.With(slog.String("op", op))
+	log.Info(
+		"starting deleting comment",
+		slog.Int("comment-id", commentId),
+		slog.Int("user-id", userId),
+	)
+	defer log.Info("deleting comment ended")
+
+	var err error
+	sendErr := func(err error) error {
+		return errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to delete - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, c.timeout)
+	defer cncl()
+
+	if err = c.edtr.DeleteComment(ctx, commentId, userId); err != nil {
+		return sendErr(c.editErr(log, err, commentId, userId))
+	}
+
+	return nil
+}
+
+// List returns page of direct replies to the parent comment of the post,
+// top-level comments if parentId is zero, oldest first, and token of the next
+// page. Every comment carries its' first replies, the rest are listed by
+// parent. The viewer must be able to read the post.
+// Only [ErrInternal], [ErrNotFound] or [ErrInvalidToken] can be returned as error
+func (c *CommentService) List(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	parentId int,
+	pageToken string,
+	pageSize int,
+) ([]models.Comment, string, error) {
+	const op = "comment-service.List"
+	log := c.log.With(slog.String("op", op))
+	log.Info(
+		"starting listing comments",
+		slog.Int("viewer-id", viewer.Id),
+		slog.Int("post-id", postId),
+		slog.Int("parent-id", parentId),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("listing comments ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Comment, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	after, err := cursor.Decode(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, c.timeout)
+	defer cncl()
+
+	if err = access.CheckPostReadable(ctx, log, c.prvdr, viewer, postId); err != nil {
+		return sendErr(err)
+	}
+
+	limit := paging.Limit(pageSize)
+	comments, err := c.prvdr.Comments(ctx, postId, parentId, after, limit+1)
+	if err != nil {
+		log.Error("failed to list comments", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	comments, nextToken := paging.Cut(comments, limit, func(last models.Comment) string {
+		return cursor.Encode(cursor.Cursor{CreatedAt: last.CreatedAt, PostId: last.Id})
+	})
+
+	parentIds := make([]int, 0, len(comments))
+	for _, comment := range comments {
+		if comment.RepliesCount > 0 {
+			parentIds = append(parentIds, comment.Id)
+		}
+	}
+	if len(parentIds) == 0 {
+		return comments, nextToken, nil
+	}
+
+	replies, err := c.prvdr.Replies(ctx, parentIds, previewReplies)
+	if err != nil {
+		log.Error("failed to list replies", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return attachReplies(comments, replies), nextToken, nil
+}
+
+// editErr converts error of editing or deleting the comment.
+// Only [ErrInternal], [ErrNotFound] or [ErrNotCreator] are returned
+func (c *CommentService) editErr(
+	log *slog.Logger,
+	err error,
+	commentId int,
+	userId int,
+) error {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		log.Warn("comment is not found", slog.Int("comment-id", commentId), sl.Err(err))
+		return ErrNotFound
+	case errors.Is(err, storage.ErrNotCreator):
+		log.Warn(
+			"user is not creator of the comment",
+			slog.Int("comment-id", commentId),
+			slog.Int("user-id", userId),
+			sl.Err(err),
+		)
+		return ErrNotCreator
+	}
+
+	log.Error("failed to change comment", sl.Err(err))
+	return ErrInternal
+}
+
+// attachReplies puts every reply into Replies of its' parent
+func attachReplies(comments []models.Comment, replies []models.Comment) []models.Comment {
+	byParent := make(map[int][]models.Comment, len(comments))
+	for _, reply := range replies {
+		byParent[reply.ParentId] = append(byParent[reply.ParentId], reply)
+	}
+
+	for i := range comments {
+		comments[i].Replies = byParent[comments[i].Id]
+	}
+
+	return comments
+}
@@ -0,0 +1,16 @@
+package comments
+
+import (
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/service/access"
+)
+
+var (
+	ErrInternal       = access.ErrInternal
+	ErrNotFound       = access.ErrNotFound
+	ErrNotCreator     = errors.New("user is not creator")
+	ErrUserNotFound   = access.ErrUserNotFound
+	ErrParentNotFound = errors.New("parent comment does not exist")
+	ErrInvalidToken   = errors.New("invalid page token")
+)
@@ -0,0 +1,9 @@
+package extraresources
+
+import (
+	"context"
+)
+
+type UserProvider interface {
+	Exists(ctx context.Context, uuid int) (isExists bool, err error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+)
+
+type Editor interface {
+	// EditComment replaces content of the comment if the user is its
+	// creator. Return values: edited comment, error
+	EditComment(
+		ctx context.Context,
+		commentId int,
+		userId int,
+		content string,
+	) (models.Comment, error)
+
+	// DeleteComment deletes the comment if the user is its creator.
+	// Return values: error
+	DeleteComment(
+		ctx context.Context,
+		commentId int,
+		userId int,
+	) error
+}
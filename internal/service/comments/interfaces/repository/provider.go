@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+)
+
+type Provider interface {
+	// Post returns the post if the viewer can read it.
+	// Return values: post, error
+	Post(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+	) (models.Post, error)
+
+	// Comments returns at most limit direct replies to the parent comment,
+	// top-level comments for zero parentId, after the cursor, oldest first.
+	// Return values: comments, error
+	Comments(
+		ctx context.Context,
+		postId int,
+		parentId int,
+		after cursor.Cursor,
+		limit int,
+	) ([]models.Comment, error)
+
+	// Replies returns at most limit oldest direct replies to every
+	// comment of the parentIds. Return values: replies, error
+	Replies(
+		ctx context.Context,
+		parentIds []int,
+		limit int,
+	) ([]models.Comment, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+)
+
+type Saver interface {
+	// SaveComment saves the comment to the post.
+	// Return values: comment with id and creation time, error
+	SaveComment(
+		ctx context.Context,
+		comment models.Comment,
+	) (models.Comment, error)
+}
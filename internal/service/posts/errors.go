@@ -2,11 +2,20 @@ package posts
 
 import (
 	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/service/access"
 )
 
 var (
-	ErrInternal     = errors.New("internal error")
-	ErrNotFound     = errors.New("not found")
+	ErrInternal     = access.ErrInternal
+	ErrNotFound     = access.ErrNotFound
 	ErrNotCreator   = errors.New("user is not creator")
-	ErrUserNotFound = errors.New("user does not exist")
+	ErrUserNotFound = access.ErrUserNotFound
+	ErrInvalidToken = errors.New("invalid page token")
+	ErrTooManyIds   = errors.New("too many ids")
+	ErrPublished    = errors.New("post is already published")
+	ErrInvalidTime  = errors.New("invalid publishing time")
+	ErrConflict     = errors.New("post was changed concurrently")
+	ErrKeyReused    = errors.New("idempotency key is reused with another payload")
+	ErrTooLarge     = errors.New("revisions are too large to diff")
 )
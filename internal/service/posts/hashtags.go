@@ -0,0 +1,55 @@
+package posts
+
+import (
+	"slices"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/hashtags"
+	"github.com/IlianBuh/Post-service/internal/lib/render"
+)
+
+// inferThemes returns themes inferred from hashtags of the content which are
+// not among the themes. Markdown content is parsed by its plain text, so
+// headings and links are not taken for hashtags
+func (p *PostService) inferThemes(
+	content string,
+	format models.ContentFormat,
+	rendered models.RenderedContent,
+	themes []string,
+) []string {
+	if format == models.FormatMarkdown {
+		content = render.PlainText(rendered.HTML)
+	}
+
+	return hashtags.Infer(content, themes, p.hashtags)
+}
+
+// inferUpdatedThemes returns themes inferred from hashtags of the new content
+// which the post does not have after the update. If the mask does not replace
+// themes, the current themes of the post are taken with addThemes and without
+// removeThemes, removeThemes are never inferred
+func (p *PostService) inferUpdatedThemes(
+	content string,
+	format models.ContentFormat,
+	rendered models.RenderedContent,
+	themes []string,
+	mask models.UpdateMask,
+	addThemes []string,
+	removeThemes []string,
+	current models.Post,
+) []string {
+	if !mask.Themes {
+		themes = make([]string, 0, len(current.Themes)+len(addThemes))
+		for _, theme := range append(current.Themes, addThemes...) {
+			if !slices.Contains(removeThemes, theme) && !slices.Contains(themes, theme) {
+				themes = append(themes, theme)
+			}
+		}
+	}
+
+	// explicitly removed themes are not inferred again
+	return slices.DeleteFunc(
+		p.inferThemes(content, format, rendered, themes),
+		func(theme string) bool { return slices.Contains(removeThemes, theme) },
+	)
+}
@@ -0,0 +1,62 @@
+package posts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+)
+
+// collectIdempotencyKey collects the idempotency key of the creating request.
+// Fingerprint of the key is hash of the normalized request payload,
+// so the same key with another payload can be detected. Empty key
+// is converted to zero idempotency key
+func collectIdempotencyKey(
+	key string,
+	ttl time.Duration,
+	login string,
+	header string,
+	content string,
+	format models.ContentFormat,
+	themes []string,
+	status models.PostStatus,
+	publishAt time.Time,
+	visibility models.Visibility,
+) (models.IdempotencyKey, error) {
+	if key == "" {
+		return models.IdempotencyKey{}, nil
+	}
+
+	payload, err := json.Marshal(struct {
+		Login      string               `json:"login"`
+		Header     string               `json:"header"`
+		Content    string               `json:"content"`
+		Format     models.ContentFormat `json:"format"`
+		Themes     []string             `json:"themes"`
+		Status     models.PostStatus    `json:"status"`
+		PublishAt  time.Time            `json:"publish_at"`
+		Visibility models.Visibility    `json:"visibility"`
+	}{
+		Login:      login,
+		Header:     header,
+		Content:    content,
+		Format:     format,
+		Themes:     themes,
+		Status:     status,
+		PublishAt:  publishAt.UTC(),
+		Visibility: visibility,
+	})
+	if err != nil {
+		return models.IdempotencyKey{}, err
+	}
+
+	sum := sha256.Sum256(payload)
+
+	return models.IdempotencyKey{
+		Key:         key,
+		Fingerprint: hex.EncodeToString(sum[:]),
+		TTL:         ttl,
+	}, nil
+}
@@ -7,4 +7,8 @@ import (
 
 type UserProvider interface {
 	Exists(ctx context.Context, uuid int) (isExists bool, err error)
+
+	// UserByLogin returns id of the user with exactly the login.
+	// Return values: uuid, isExists, error
+	UserByLogin(ctx context.Context, login string) (uuid int, isExists bool, err error)
 }
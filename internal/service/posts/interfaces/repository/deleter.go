@@ -5,10 +5,19 @@ import (
 )
 
 type Deleter interface {
-	// Delete deletes the record. Return values: postId, error
+	// Delete moves the record to trash if it has the expected version. Zero
+	// version skips the check. Return values: postId, error
 	Delete(
 		ctx context.Context,
 		postId int,
 		userId int,
+		version int,
+	) error
+
+	// RestorePost takes the record out of trash. Return values: error
+	RestorePost(
+		ctx context.Context,
+		postId int,
+		userId int,
 	) error
 }
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+)
+
+// Provider reads posts on behalf of the viewer. Posts hidden from the viewer
+// are treated as missing, unlisted posts are returned only by id
+type Provider interface {
+	// Post returns the post with all related themes. Return values: post, error
+	Post(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+	) (models.Post, error)
+
+	// Posts returns existing posts with ids from the list in any order.
+	// Return values: posts, error
+	Posts(
+		ctx context.Context,
+		viewer models.Viewer,
+		postIds []int,
+	) ([]models.Post, error)
+
+	// PostsByUser returns at most limit posts of the user, newest first,
+	// that are placed after the cursor. Return values: posts, error
+	PostsByUser(
+		ctx context.Context,
+		viewer models.Viewer,
+		userId int,
+		after cursor.Cursor,
+		limit int,
+	) ([]models.Post, error)
+
+	// PostsByThemes returns at most limit posts, newest first, that are placed
+	// after the cursor and match themes. If matchAll is set, post must have all
+	// themes, otherwise any of them. Zero userId means posts of any author.
+	// Return values: posts, error
+	PostsByThemes(
+		ctx context.Context,
+		viewer models.Viewer,
+		themes []string,
+		matchAll bool,
+		userId int,
+		after cursor.Cursor,
+		limit int,
+	) ([]models.Post, error)
+
+	// Search returns at most limit posts matching full-text query, ordered by
+	// rank, that are placed after the cursor. Empty themes and zero userId
+	// disable the corresponding filters. Return values: hits, error
+	Search(
+		ctx context.Context,
+		viewer models.Viewer,
+		query string,
+		themes []string,
+		userId int,
+		after cursor.Rank,
+		limit int,
+	) ([]models.SearchHit, error)
+
+	// Feed returns at most limit posts of the authors, newest first, that are
+	// placed after the cursor. Non-empty themes require posts to have any of
+	// them, posts with excluded themes or ids are skipped.
+	// Return values: posts, error
+	Feed(
+		ctx context.Context,
+		viewer models.Viewer,
+		authorIds []int,
+		themes []string,
+		excludeThemes []string,
+		excludePostIds []int,
+		after cursor.Cursor,
+		limit int,
+	) ([]models.Post, error)
+
+	// Trash returns at most limit trashed posts of the user, the most recently
+	// deleted first, that are placed after the cursor keyed on deletion time.
+	// Return values: posts, error
+	Trash(
+		ctx context.Context,
+		userId int,
+		after cursor.Cursor,
+		limit int,
+	) ([]models.Post, error)
+
+	// Unpublished returns at most limit drafts and scheduled posts of the
+	// user, newest first, that are placed after the cursor.
+	// Return values: posts, error
+	Unpublished(
+		ctx context.Context,
+		userId int,
+		after cursor.Cursor,
+		limit int,
+	) ([]models.Post, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type Publisher interface {
+	// Publish publishes the unpublished record at publishAt, right now if it
+	// is not in the future. Return values: error
+	Publish(
+		ctx context.Context,
+		postId int,
+		userId int,
+		publishAt time.Time,
+	) error
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+)
+
+type RevisionProvider interface {
+	// Revisions returns at most limit revisions of the post, the newest first,
+	// which are older than before. Zero before means no bound.
+	// Return values: revisions, error
+	Revisions(
+		ctx context.Context,
+		postId int,
+		before int,
+		limit int,
+	) ([]models.Revision, error)
+
+	// Revision returns the revision of the post. Return values: revision, error
+	Revision(
+		ctx context.Context,
+		postId int,
+		revision int,
+	) (models.Revision, error)
+}
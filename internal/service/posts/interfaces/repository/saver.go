@@ -2,16 +2,30 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
 )
 
 type Saver interface {
-	// Save saves the record. Return values: postId, error
+	// Save saves the record with the status and visibility. publishAt is the
+	// publishing time of the scheduled record. rendered is derived from the
+	// content by its format. mentions are users mentioned in the record. The
+	// record is not saved twice with the same non-zero idempotency key.
+	// Return values: postId, error
 	Save(
 		ctx context.Context,
 		userId int,
 		login string,
 		header string,
 		contetn string,
+		format models.ContentFormat,
+		rendered models.RenderedContent,
 		themes []string,
+		status models.PostStatus,
+		publishAt time.Time,
+		visibility models.Visibility,
+		mentions []models.Mention,
+		idemKey models.IdempotencyKey,
 	) (int, error)
 }
@@ -2,16 +2,29 @@ package repository
 
 import (
 	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
 )
 
 type Updater interface {
-	// Update updates the record. Return values: postId, error
+	// Update updates the record if it has the expected version. Zero version
+	// skips the check. Only fields named in the mask are replaced, format and
+	// rendered are replaced with the content. addThemes and removeThemes are
+	// applied after the mask. mentions replace mentions of the record if the
+	// header or the content is replaced. Return values: postId, error
 	Update(
 		ctx context.Context,
 		postId int,
 		userId int,
 		header string,
 		contetn string,
+		format models.ContentFormat,
+		rendered models.RenderedContent,
 		themes []string,
+		mask models.UpdateMask,
+		addThemes []string,
+		removeThemes []string,
+		mentions []models.Mention,
+		version int,
 	) (int, error)
 }
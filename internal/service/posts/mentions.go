@@ -0,0 +1,92 @@
+package posts
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/mentions"
+	"github.com/IlianBuh/Post-service/internal/lib/render"
+)
+
+// maxMentions limits number of logins resolved for one post,
+// the rest of mentions are ignored
+const maxMentions = 20
+
+// mentions parses mentions out of the header and the content and resolves
+// mentioned logins to users. Unknown logins and the author are skipped.
+// Markdown content is parsed by its plain text, so links and markup are
+// not taken for mentions.
+// Only [ErrInternal] can be returned as error
+func (p *PostService) mentions(
+	ctx context.Context,
+	userId int,
+	header string,
+	content string,
+	format models.ContentFormat,
+	rendered models.RenderedContent,
+) ([]models.Mention, error) {
+	const op = "post-service.mentions"
+	log := p.log.With(slog.String("op", op))
+
+	if format == models.FormatMarkdown {
+		content = render.PlainText(rendered.HTML)
+	}
+
+	logins := mentions.Parse(header, content)
+	if len(logins) > maxMentions {
+		log.Warn(
+			"too many mentions, the rest are ignored",
+			slog.Int("mentions", len(logins)),
+		)
+		logins = logins[:maxMentions]
+	}
+
+	res := make([]models.Mention, 0, len(logins))
+	for _, login := range logins {
+		uuid, ok, err := p.usrPrvdr.UserByLogin(ctx, login)
+		if err != nil {
+			log.Error("failed to resolve login", slog.String("login", login), sl.Err(err))
+			return nil, errs.Fail(op, ErrInternal)
+		}
+		if !ok || uuid == userId {
+			continue
+		}
+
+		res = append(res, models.Mention{UserId: uuid, Login: login})
+	}
+
+	return res, nil
+}
+
+// updatedMentions returns users mentioned in the post after the update. The
+// field which is not replaced by the mask is taken from the current post.
+// Only [ErrInternal] can be returned as error
+func (p *PostService) updatedMentions(
+	ctx context.Context,
+	userId int,
+	header string,
+	content string,
+	format models.ContentFormat,
+	rendered models.RenderedContent,
+	mask models.UpdateMask,
+	current models.Post,
+) ([]models.Mention, error) {
+	const op = "post-service.updatedMentions"
+
+	if !mask.Header {
+		header = current.Header
+	}
+	if !mask.Content {
+		content, format, rendered = current.Content, current.ContentFormat, current.Rendered
+	}
+
+	res, err := p.mentions(ctx, userId, header, content, format, rendered)
+	if err != nil {
+		return nil, errs.Fail(op, err)
+	}
+
+	return res, nil
+}
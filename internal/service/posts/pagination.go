@@ -0,0 +1,25 @@
+package posts
+
+import (
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+)
+
+// maxFeedIds limits number of authors and excluded posts of a feed
+const maxFeedIds = 1000
+
+// postToken returns token of the page which follows the post
+func postToken(last models.Post) string {
+	return cursor.Encode(cursor.Cursor{CreatedAt: last.CreatedAt, PostId: last.Id})
+}
+
+// trashToken returns token of the trash page which follows the post,
+// trash is keyed on deletion time
+func trashToken(last models.Post) string {
+	return cursor.Encode(cursor.Cursor{CreatedAt: last.DeletedAt, PostId: last.Id})
+}
+
+// hitToken returns token of the page which follows the search hit
+func hitToken(last models.SearchHit) string {
+	return cursor.EncodeRank(cursor.Rank{Rank: last.Rank, PostId: last.Post.Id})
+}
@@ -7,20 +7,33 @@ import (
 
 	"errors"
 
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
 	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/hashtags"
 	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/normalize"
+	"github.com/IlianBuh/Post-service/internal/lib/paging"
+	"github.com/IlianBuh/Post-service/internal/lib/render"
+	"github.com/IlianBuh/Post-service/internal/service/access"
 	extraresources "github.com/IlianBuh/Post-service/internal/service/posts/interfaces/extra-resources"
 	"github.com/IlianBuh/Post-service/internal/service/posts/interfaces/repository"
 	"github.com/IlianBuh/Post-service/internal/storage"
 )
 
 type PostService struct {
-	log      *slog.Logger
-	svr      repository.Saver
-	updtr    repository.Updater
-	dltr     repository.Deleter
-	timeout  time.Duration
-	usrPrvdr extraresources.UserProvider
+	log       *slog.Logger
+	svr       repository.Saver
+	updtr     repository.Updater
+	dltr      repository.Deleter
+	prvdr     repository.Provider
+	rvsnPrvdr repository.RevisionProvider
+	pblshr    repository.Publisher
+	timeout   time.Duration
+	usrPrvdr  extraresources.UserProvider
+	hashtags  hashtags.Options
+	// idemTTL is time after which idempotency key can be used again
+	idemTTL time.Duration
 }
 
 func New(
@@ -28,29 +41,55 @@ func New(
 	svr repository.Saver,
 	updtr repository.Updater,
 	dltr repository.Deleter,
+	prvdr repository.Provider,
+	rvsnPrvdr repository.RevisionProvider,
+	pblshr repository.Publisher,
 	timeout time.Duration,
 	usrPrvdr extraresources.UserProvider,
+	hashtags hashtags.Options,
+	idemTTL time.Duration,
 ) *PostService {
 	return &PostService{
-		log:      log,
-		svr:      svr,
-		updtr:    updtr,
-		dltr:     dltr,
-		timeout:  timeout,
-		usrPrvdr: usrPrvdr,
+		log:       log,
+		svr:       svr,
+		updtr:     updtr,
+		dltr:      dltr,
+		prvdr:     prvdr,
+		rvsnPrvdr: rvsnPrvdr,
+		pblshr:    pblshr,
+		timeout:   timeout,
+		usrPrvdr:  usrPrvdr,
+		hashtags:  hashtags,
+		idemTTL:   idemTTL,
 	}
 }
 
-// Create creates new post and returns new posts' id or error.
-// Only [ErrInternal] or [ErrUserNotFound] can be returned
+// Create creates new post and returns new posts' id or error. Empty status
+// means published post, scheduled post requires publishAt in the future and
+// publishAt of other posts is ignored. Empty visibility means public post.
+// Empty format means plain content, markdown content is rendered to HTML.
+// If inferThemes is set, hashtags of the content are merged into the themes
+// and returned as inferred themes.
+// Users mentioned as @login in the header or the content are saved with the
+// post. Repeated request with the same non-empty idempotency key returns id of
+// the post created by the first request, the key is scoped by the user and
+// can be used again after the idempotency TTL.
+// Only [ErrInternal], [ErrUserNotFound], [ErrInvalidTime] or [ErrKeyReused]
+// can be returned
 func (p *PostService) Create(
 	ctx context.Context,
 	userId int,
 	login string,
 	header string,
 	content string,
+	format models.ContentFormat,
 	themes []string,
-) (int, error) {
+	inferThemes bool,
+	status models.PostStatus,
+	publishAt time.Time,
+	visibility models.Visibility,
+	idempotencyKey string,
+) (int, []string, error) {
 	const op = "post-service.Create"
 	log := p.log.With(slog.String("op", op))
 	log.Info(
@@ -58,13 +97,19 @@ func (p *PostService) Create(
 		slog.Int("user-id", userId),
 		slog.String("header", header),
 		slog.String("content", content),
+		slog.String("format", string(format)),
 		slog.Any("themes", themes),
+		slog.Bool("infer-themes", inferThemes),
+		slog.String("status", string(status)),
+		slog.Time("publish-at", publishAt),
+		slog.String("visibility", string(visibility)),
+		slog.String("idempotency-key", idempotencyKey),
 	)
 	defer log.Info("creating post ended")
 
 	var err error
-	sendErr := func(err error) (int, error) {
-		return 0, errs.Fail(op, err)
+	sendErr := func(err error) (int, []string, error) {
+		return 0, nil, errs.Fail(op, err)
 	}
 
 	if err = ctx.Err(); err != nil {
@@ -74,32 +119,126 @@ func (p *PostService) Create(
 	ctx, cncl := context.WithTimeout(ctx, p.timeout)
 	defer cncl()
 
-	err = p.checkUserExisting(ctx, userId)
+	switch status {
+	case "":
+		status = models.StatusPublished
+		publishAt = time.Time{}
+	case models.StatusScheduled:
+		if !publishAt.After(time.Now()) {
+			log.Warn("publishing time is not in the future", slog.Time("publish-at", publishAt))
+			return sendErr(ErrInvalidTime)
+		}
+	default:
+		publishAt = time.Time{}
+	}
+	if visibility == "" {
+		visibility = models.VisibilityPublic
+	}
+	if format == "" {
+		format = models.FormatPlain
+	}
+
+	err = access.CheckUserExisting(ctx, log, p.usrPrvdr, userId)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	rendered, err := render.Content(format, content)
+	if err != nil {
+		log.Error("failed to render content", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	themes = normalize.Themes(themes)
+	var inferred []string
+	if inferThemes {
+		inferred = p.inferThemes(content, format, rendered, themes)
+		themes = append(themes, inferred...)
+	}
+
+	// inferred themes are part of the payload, so the
+	// switch is taken into account by the fingerprint
+	idemKey, err := collectIdempotencyKey(
+		idempotencyKey,
+		p.idemTTL,
+		login,
+		header,
+		content,
+		format,
+		themes,
+		status,
+		publishAt,
+		visibility,
+	)
+	if err != nil {
+		log.Error("failed to collect idempotency key", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	mentions, err := p.mentions(ctx, userId, header, content, format, rendered)
 	if err != nil {
 		return sendErr(err)
 	}
 
-	postId, err := p.svr.Save(ctx, userId, login, header, content, themes)
+	postId, err := p.svr.Save(
+		ctx,
+		userId,
+		login,
+		header,
+		content,
+		format,
+		rendered,
+		themes,
+		status,
+		publishAt,
+		visibility,
+		mentions,
+		idemKey,
+	)
 	if err != nil {
+		if errors.Is(err, storage.ErrKeyReused) {
+			log.Warn(
+				"idempotency key is reused with another payload",
+				slog.String("idempotency-key", idempotencyKey),
+				sl.Err(err),
+			)
+			return sendErr(ErrKeyReused)
+		}
+
 		log.Error("failed to save post", sl.Err(err))
 		return sendErr(ErrInternal)
 	}
 
 	log.Info("post is saved")
-	return postId, nil
+	return postId, inferred, nil
 }
 
-// Update updates post and returns posts' id, which must be equal
-// to postId or error.
-// Only [ErrInternal], [ErrNotCreator] or [ErrNotFound] can be returned as an error
+// Update updates post and returns themes inferred from hashtags or error.
+// Only fields named in the mask are replaced, so they can be cleared. Zero
+// mask names only fields with non-empty values. Content is replaced together
+// with its format, empty format keeps the format of the post. addThemes and
+// removeThemes are applied after the mask. If inferThemes is set and the
+// content is replaced, hashtags of the content are added to the themes.
+// Mentions are parsed again if the header or the content is replaced.
+// The post must have the expected version, zero version skips the check. If
+// mentions or themes depend on fields which are not replaced, the update fails
+// with [ErrConflict] when the post is changed after they are read.
+// Only [ErrInternal], [ErrNotCreator], [ErrNotFound] or [ErrConflict] can be
+// returned as an error
 func (p *PostService) Update(
 	ctx context.Context,
 	userId int,
 	postId int,
 	header string,
 	content string,
+	format models.ContentFormat,
 	themes []string,
-) error {
+	mask models.UpdateMask,
+	addThemes []string,
+	removeThemes []string,
+	inferThemes bool,
+	version int,
+) ([]string, error) {
 	const op = "post-service.Update"
 	log := p.log.With("op", op)
 	log.Info(
@@ -108,13 +247,19 @@ func (p *PostService) Update(
 		slog.Int("user-id", userId),
 		slog.String("header", header),
 		slog.String("content", content),
+		slog.String("format", string(format)),
 		slog.Any("themes", themes),
+		slog.Any("mask", mask),
+		slog.Any("add-themes", addThemes),
+		slog.Any("remove-themes", removeThemes),
+		slog.Bool("infer-themes", inferThemes),
+		slog.Int("version", version),
 	)
 	defer log.Info("updating post ended")
 
 	var err error
-	sendErr := func(err error) error {
-		return errs.Fail(op, err)
+	sendErr := func(err error) ([]string, error) {
+		return nil, errs.Fail(op, err)
 	}
 
 	if err = ctx.Err(); err != nil {
@@ -124,7 +269,95 @@ func (p *PostService) Update(
 	ctx, cncl := context.WithTimeout(ctx, p.timeout)
 	defer cncl()
 
-	postId, err = p.updtr.Update(ctx, postId, userId, header, content, themes)
+	if mask.IsZero() {
+		mask = models.UpdateMask{
+			Header:  header != "",
+			Content: content != "",
+			Themes:  len(themes) != 0,
+		}
+	}
+
+	// fields which are not replaced are taken from the current post. Its'
+	// version is pinned, so the update fails if the post is changed after
+	// the read and nothing is derived from the stale state
+	var current models.Post
+	if mask.Header != mask.Content ||
+		mask.Content && (format == "" || inferThemes && !mask.Themes) {
+		current, err = p.currentPost(ctx, userId, postId, version)
+		if err != nil {
+			return sendErr(err)
+		}
+		version = current.Version
+	}
+
+	var rendered models.RenderedContent
+	if mask.Content {
+		if format == "" {
+			format = current.ContentFormat
+		}
+
+		rendered, err = render.Content(format, content)
+		if err != nil {
+			log.Error("failed to render content", sl.Err(err))
+			return sendErr(ErrInternal)
+		}
+	}
+
+	var mentions []models.Mention
+	if mask.Header || mask.Content {
+		mentions, err = p.updatedMentions(
+			ctx,
+			userId,
+			header,
+			content,
+			format,
+			rendered,
+			mask,
+			current,
+		)
+		if err != nil {
+			return sendErr(err)
+		}
+	}
+
+	themes = normalize.Themes(themes)
+	addThemes = normalize.Themes(addThemes)
+	removeThemes = normalize.Themes(removeThemes)
+
+	var inferred []string
+	if inferThemes && mask.Content {
+		inferred = p.inferUpdatedThemes(
+			content,
+			format,
+			rendered,
+			themes,
+			mask,
+			addThemes,
+			removeThemes,
+			current,
+		)
+		if mask.Themes {
+			themes = append(themes, inferred...)
+		} else {
+			addThemes = append(addThemes, inferred...)
+		}
+	}
+
+	postId, err = p.updtr.Update(
+		ctx,
+		postId,
+		userId,
+		header,
+		content,
+		format,
+		rendered,
+		themes,
+		mask,
+		addThemes,
+		removeThemes,
+		mentions,
+		version,
+	)
 	if err != nil {
 		switch {
 		case errors.Is(err, storage.ErrNotFound):
@@ -142,28 +375,40 @@ func (p *PostService) Update(
 				sl.Err(err),
 			)
 			return sendErr(ErrNotCreator)
+		case errors.Is(err, storage.ErrConflict):
+			log.Warn(
+				"version of the post does not match",
+				slog.Int("post-id", postId),
+				slog.Int("version", version),
+				sl.Err(err),
+			)
+			return sendErr(ErrConflict)
 		}
 
 		log.Error("failed to update record", sl.Err(err))
 		return sendErr(ErrInternal)
 	}
 
-	return nil
+	return inferred, nil
 }
 
 // Delete deletes post with postId. Return posts' id which must be
-// equal to postId or error.
-// Only [ErrInternal] or [ErrNotCreator] can be returned as error
+// equal to postId or error. The post must have the expected version,
+// zero version skips the check. Missing and already trashed posts are not found.
+// Only [ErrInternal], [ErrNotFound], [ErrNotCreator] or [ErrConflict] can be
+// returned as error
 func (p *PostService) Delete(
 	ctx context.Context,
 	postId int,
 	userId int,
+	version int,
 ) error {
 	const op = "post-service.Delete"
 	log := p.log.With("op", op)
 	log.Info("starting deleting post",
 		slog.Int("post-id", postId),
 		slog.Int("user-id", userId),
+		slog.Int("version", version),
 	)
 	defer log.Info("deleting ended")
 
@@ -180,9 +425,17 @@ func (p *PostService) Delete(
 	ctx, cncl := context.WithTimeout(ctx, p.timeout)
 	defer cncl()
 
-	err = p.dltr.Delete(ctx, postId, userId)
+	err = p.dltr.Delete(ctx, postId, userId, version)
 	if err != nil {
-		if errors.Is(err, storage.ErrNotCreator) {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			log.Warn(
+				"post with the id is not found",
+				slog.Int("post-id", postId),
+				sl.Err(err),
+			)
+			return sendErr(ErrNotFound)
+		case errors.Is(err, storage.ErrNotCreator):
 			log.Warn(
 				"user is not creator of the post",
 				slog.Int("post-id", postId),
@@ -190,6 +443,14 @@ func (p *PostService) Delete(
 				sl.Err(err),
 			)
 			return sendErr(ErrNotCreator)
+		case errors.Is(err, storage.ErrConflict):
+			log.Warn(
+				"version of the post does not match",
+				slog.Int("post-id", postId),
+				slog.Int("version", version),
+				sl.Err(err),
+			)
+			return sendErr(ErrConflict)
 		}
 
 		log.Error("failed to delete post", sl.Err(err))
@@ -199,31 +460,400 @@ func (p *PostService) Delete(
 	return nil
 }
 
-// checkUserExisting checks if user exists. If user does not exist,
-// return error, otherwise return nil.
-//
-// It can return either [ErrInternal] or [ErrUserNotFound]
-func (p *PostService) checkUserExisting(
+// Get returns post with postId if the viewer can read it.
+// Only [ErrInternal] or [ErrNotFound] can be returned as error
+func (p *PostService) Get(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+) (models.Post, error) {
+	const op = "post-service.Get"
+	log := p.log.With(slog.String("op", op))
+	log.Info("starting getting post", slog.Int("post-id", postId))
+	defer log.Info("getting post ended")
+
+	var err error
+	sendErr := func(err error) (models.Post, error) {
+		return models.Post{}, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to get - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	post, err := p.prvdr.Post(ctx, viewer, postId)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn(
+				"post with the id is not found",
+				slog.Int("post-id", postId),
+				sl.Err(err),
+			)
+			return sendErr(ErrNotFound)
+		}
+
+		log.Error("failed to get post", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return post, nil
+}
+
+// GetMany returns posts with postIds in the order of the ids and ids of the
+// posts that do not exist or are hidden from the viewer. Duplicate ids are
+// returned once.
+// Only [ErrInternal] or [ErrTooManyIds] can be returned as error
+func (p *PostService) GetMany(
 	ctx context.Context,
+	viewer models.Viewer,
+	postIds []int,
+) ([]models.Post, []int, error) {
+	const op = "post-service.GetMany"
+	log := p.log.With(slog.String("op", op))
+	log.Info("starting getting posts", slog.Any("post-ids", postIds))
+	defer log.Info("getting posts ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Post, []int, error) {
+		return nil, nil, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to get - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	postIds = uniqueIds(postIds)
+	if len(postIds) > paging.MaxPageSize {
+		log.Warn("too many ids", slog.Int("count", len(postIds)))
+		return sendErr(ErrTooManyIds)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	found, err := p.prvdr.Posts(ctx, viewer, postIds)
+	if err != nil {
+		log.Error("failed to get posts", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	byId := make(map[int]models.Post, len(found))
+	for _, post := range found {
+		byId[post.Id] = post
+	}
+
+	posts := make([]models.Post, 0, len(found))
+	missing := make([]int, 0, len(postIds)-len(found))
+	for _, id := range postIds {
+		post, ok := byId[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, missing, nil
+}
+
+// ListByUser returns page of users' posts, newest first, and token of the
+// next page. Empty pageToken means the first page, empty returned token means
+// there are no more posts.
+// Only [ErrInternal] or [ErrInvalidToken] can be returned as error
+func (p *PostService) ListByUser(
+	ctx context.Context,
+	viewer models.Viewer,
 	userId int,
-) error {
-	const op = "post-service.checkUserExisting"
+	pageToken string,
+	pageSize int,
+) ([]models.Post, string, error) {
+	const op = "post-service.ListByUser"
 	log := p.log.With(slog.String("op", op))
-	log.Info("starting to check user existing")
-	defer log.Info("checking ended")
+	log.Info(
+		"starting listing users' posts",
+		slog.Int("user-id", userId),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("listing users' posts ended")
 
-	ok, err := p.usrPrvdr.Exists(ctx, userId)
+	var err error
+	sendErr := func(err error) ([]models.Post, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	after, err := cursor.Decode(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	limit := paging.Limit(pageSize)
+	posts, err := p.prvdr.PostsByUser(ctx, viewer, userId, after, limit+1)
 	if err != nil {
-		log.Error("failed to check users' existsing", sl.Err(err))
-		return errs.Fail(op, ErrInternal)
+		log.Error("failed to list users' posts", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	posts, nextToken := paging.Cut(posts, limit, postToken)
+
+	return posts, nextToken, nil
+}
+
+// ListByThemes returns page of posts, newest first, that have any of themes,
+// or all of them if matchAll is set, and token of the next page. Zero userId
+// means posts of any author.
+// Only [ErrInternal] or [ErrInvalidToken] can be returned as error
+func (p *PostService) ListByThemes(
+	ctx context.Context,
+	viewer models.Viewer,
+	themes []string,
+	matchAll bool,
+	userId int,
+	pageToken string,
+	pageSize int,
+) ([]models.Post, string, error) {
+	const op = "post-service.ListByThemes"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting listing posts by themes",
+		slog.Any("themes", themes),
+		slog.Bool("match-all", matchAll),
+		slog.Int("user-id", userId),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("listing posts by themes ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Post, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
 	}
-	if !ok {
+
+	after, err := cursor.Decode(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	limit := paging.Limit(pageSize)
+	posts, err := p.prvdr.PostsByThemes(
+		ctx, viewer, normalize.Themes(themes), matchAll, userId, after, limit+1,
+	)
+	if err != nil {
+		log.Error("failed to list posts by themes", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	posts, nextToken := paging.Cut(posts, limit, postToken)
+
+	return posts, nextToken, nil
+}
+
+// Feed returns page of posts of the authors merged into one timeline, newest
+// first, and token of the next page. Non-empty themes require posts to have any
+// of them, posts with excluded themes or ids are skipped.
+// Only [ErrInternal], [ErrInvalidToken] or [ErrTooManyIds] can be returned as error
+func (p *PostService) Feed(
+	ctx context.Context,
+	viewer models.Viewer,
+	authorIds []int,
+	themes []string,
+	excludeThemes []string,
+	excludePostIds []int,
+	pageToken string,
+	pageSize int,
+) ([]models.Post, string, error) {
+	const op = "post-service.Feed"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting assembling feed",
+		slog.Int("authors-count", len(authorIds)),
+		slog.Any("themes", themes),
+		slog.Any("exclude-themes", excludeThemes),
+		slog.Int("exclude-posts-count", len(excludePostIds)),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("assembling feed ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Post, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to assemble - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	authorIds, excludePostIds = uniqueIds(authorIds), uniqueIds(excludePostIds)
+	if len(authorIds) > maxFeedIds || len(excludePostIds) > maxFeedIds {
 		log.Warn(
-			"user does not exist",
-			slog.Int("uuid", userId),
+			"too many ids",
+			slog.Int("authors-count", len(authorIds)),
+			slog.Int("exclude-posts-count", len(excludePostIds)),
 		)
-		return errs.Fail(op, ErrUserNotFound)
+		return sendErr(ErrTooManyIds)
 	}
 
-	return nil
+	after, err := cursor.Decode(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	limit := paging.Limit(pageSize)
+	posts, err := p.prvdr.Feed(
+		ctx,
+		viewer,
+		authorIds,
+		normalize.Themes(themes),
+		normalize.Themes(excludeThemes),
+		excludePostIds,
+		after,
+		limit+1,
+	)
+	if err != nil {
+		log.Error("failed to assemble feed", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	posts, nextToken := paging.Cut(posts, limit, postToken)
+
+	return posts, nextToken, nil
+}
+
+// Search returns page of posts matching full-text query, the most relevant
+// first, and token of the next page. Empty themes and zero userId disable
+// filtering by themes and author.
+// Only [ErrInternal] or [ErrInvalidToken] can be returned as error
+func (p *PostService) Search(
+	ctx context.Context,
+	viewer models.Viewer,
+	query string,
+	themes []string,
+	userId int,
+	pageToken string,
+	pageSize int,
+) ([]models.SearchHit, string, error) {
+	const op = "post-service.Search"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting searching posts",
+		slog.String("query", query),
+		slog.Any("themes", themes),
+		slog.Int("user-id", userId),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("searching posts ended")
+
+	var err error
+	sendErr := func(err error) ([]models.SearchHit, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to search - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	after, err := cursor.DecodeRank(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	limit := paging.Limit(pageSize)
+	hits, err := p.prvdr.Search(
+		ctx, viewer, query, normalize.Themes(themes), userId, after, limit+1,
+	)
+	if err != nil {
+		log.Error("failed to search posts", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	hits, nextToken := paging.Cut(hits, limit, hitToken)
+
+	return hits, nextToken, nil
+}
+
+// currentPost returns the post before the update. Version of the post must
+// be equal to the expected one, zero version skips the check. The update
+// of the returned version fails if the post is changed after the read.
+// Only [ErrInternal], [ErrNotFound] or [ErrConflict] can be returned as error
+func (p *PostService) currentPost(
+	ctx context.Context,
+	userId int,
+	postId int,
+	version int,
+) (models.Post, error) {
+	const op = "post-service.currentPost"
+	log := p.log.With(slog.String("op", op))
+
+	post, err := p.prvdr.Post(ctx, models.Viewer{Id: userId}, postId)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("post is not found", slog.Int("post-id", postId), sl.Err(err))
+			return models.Post{}, errs.Fail(op, ErrNotFound)
+		}
+
+		log.Error("failed to get post", slog.Int("post-id", postId), sl.Err(err))
+		return models.Post{}, errs.Fail(op, ErrInternal)
+	}
+	if version != 0 && post.Version != version {
+		log.Warn(
+			"version of the post does not match",
+			slog.Int("post-id", postId),
+			slog.Int("version", version),
+		)
+		return models.Post{}, errs.Fail(op, ErrConflict)
+	}
+
+	return post, nil
+}
+
+// uniqueIds returns ids without duplicates keeping the original order
+func uniqueIds(ids []int) []int {
+	seen := make(map[int]struct{}, len(ids))
+	res := make([]int, 0, len(ids))
+
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+
+		seen[id] = struct{}{}
+		res = append(res, id)
+	}
+
+	return res
 }
@@ -0,0 +1,125 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/paging"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+// Publish publishes the draft or scheduled post. Zero publishAt, or publishAt
+// which is not in the future, publishes the post right now, otherwise the post
+// is scheduled. Only creator of the post can publish it.
+// Only [ErrInternal], [ErrNotFound], [ErrNotCreator] or [ErrPublished] can be
+// returned as error
+func (p *PostService) Publish(
+	ctx context.Context,
+	postId int,
+	userId int,
+	publishAt time.Time,
+) error {
+	const op = "post-service.Publish"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting publishing post",
+		slog.Int("post-id", postId),
+		slog.Int("user-id", userId),
+		slog.Time("publish-at", publishAt),
+	)
+	defer log.Info("publishing post ended")
+
+	var err error
+	sendErr := func(err error) error {
+		return errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to publish - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	err = p.pblshr.Publish(ctx, postId, userId, publishAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			log.Warn("post is not found", slog.Int("post-id", postId), sl.Err(err))
+			return sendErr(ErrNotFound)
+		case errors.Is(err, storage.ErrNotCreator):
+			log.Warn(
+				"user is not creator of the post",
+				slog.Int("post-id", postId),
+				slog.Int("user-id", userId),
+				sl.Err(err),
+			)
+			return sendErr(ErrNotCreator)
+		case errors.Is(err, storage.ErrPublished):
+			log.Warn("post is already published", slog.Int("post-id", postId), sl.Err(err))
+			return sendErr(ErrPublished)
+		}
+
+		log.Error("failed to publish post", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return nil
+}
+
+// ListUnpublished returns page of users' drafts and scheduled posts, newest
+// first, and token of the next page.
+// Only [ErrInternal] or [ErrInvalidToken] can be returned as error
+func (p *PostService) ListUnpublished(
+	ctx context.Context,
+	userId int,
+	pageToken string,
+	pageSize int,
+) ([]models.Post, string, error) {
+	const op = "post-service.ListUnpublished"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting listing unpublished posts",
+		slog.Int("user-id", userId),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("listing unpublished posts ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Post, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	after, err := cursor.Decode(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	limit := paging.Limit(pageSize)
+	posts, err := p.prvdr.Unpublished(ctx, userId, after, limit+1)
+	if err != nil {
+		log.Error("failed to list unpublished posts", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	posts, nextToken := paging.Cut(posts, limit, postToken)
+
+	return posts, nextToken, nil
+}
@@ -0,0 +1,286 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	"github.com/IlianBuh/Post-service/internal/lib/diff"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/paging"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+// ListRevisions returns page of post revisions, the newest first, and token of
+// the next page. Every revision is a state of the post before one of its' updates.
+// Revisions are listed only if the viewer can read the post.
+// Only [ErrInternal], [ErrNotFound] or [ErrInvalidToken] can be returned as error
+func (p *PostService) ListRevisions(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	pageToken string,
+	pageSize int,
+) ([]models.Revision, string, error) {
+	const op = "post-service.ListRevisions"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting listing revisions",
+		slog.Int("post-id", postId),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("listing revisions ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Revision, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	before, err := cursor.DecodeId(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	if _, err = p.revision(ctx, viewer, postId, 0); err != nil {
+		return sendErr(err)
+	}
+
+	limit := paging.Limit(pageSize)
+	revisions, err := p.rvsnPrvdr.Revisions(ctx, postId, before, limit+1)
+	if err != nil {
+		log.Error("failed to list revisions", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	if len(revisions) <= limit {
+		return revisions, "", nil
+	}
+
+	revisions = revisions[:limit]
+
+	return revisions, cursor.EncodeId(revisions[limit-1].Revision), nil
+}
+
+// GetRevision returns the revision of the post if the viewer can read the post.
+// Only [ErrInternal] or [ErrNotFound] can be returned as error
+func (p *PostService) GetRevision(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	revision int,
+) (models.Revision, error) {
+	const op = "post-service.GetRevision"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting getting revision",
+		slog.Int("post-id", postId),
+		slog.Int("revision", revision),
+	)
+	defer log.Info("getting revision ended")
+
+	var err error
+	sendErr := func(err error) (models.Revision, error) {
+		return models.Revision{}, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to get - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	if _, err = p.revision(ctx, viewer, postId, 0); err != nil {
+		return sendErr(err)
+	}
+
+	rev, err := p.revision(ctx, viewer, postId, revision)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	return rev, nil
+}
+
+// DiffRevisions returns line-based diff which turns revision from into revision
+// to. Zero revision means the current state of the post. The viewer must be
+// able to read the post. Texts longer than [diff.MaxLines] lines are not diffed.
+// Only [ErrInternal], [ErrNotFound] or [ErrTooLarge] can be returned as error
+func (p *PostService) DiffRevisions(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	from int,
+	to int,
+) (models.RevisionDiff, error) {
+	const op = "post-service.DiffRevisions"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting diffing revisions",
+		slog.Int("post-id", postId),
+		slog.Int("from", from),
+		slog.Int("to", to),
+	)
+	defer log.Info("diffing revisions ended")
+
+	var err error
+	sendErr := func(err error) (models.RevisionDiff, error) {
+		return models.RevisionDiff{}, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to diff - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	if _, err = p.revision(ctx, viewer, postId, 0); err != nil {
+		return sendErr(err)
+	}
+
+	fromRev, err := p.revision(ctx, viewer, postId, from)
+	if err != nil {
+		return sendErr(err)
+	}
+	toRev, err := p.revision(ctx, viewer, postId, to)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	header, err := diff.Lines(fromRev.Header, toRev.Header)
+	if err != nil {
+		log.Warn("header is too large to diff", sl.Err(err))
+		return sendErr(ErrTooLarge)
+	}
+	content, err := diff.Lines(fromRev.Content, toRev.Content)
+	if err != nil {
+		log.Warn("content is too large to diff", sl.Err(err))
+		return sendErr(ErrTooLarge)
+	}
+
+	added, removed := diff.Sets(fromRev.Themes, toRev.Themes)
+
+	return models.RevisionDiff{
+		Header:        header,
+		Content:       content,
+		AddedThemes:   added,
+		RemovedThemes: removed,
+	}, nil
+}
+
+// RestoreRevision makes the revision the current state of the post. It is the
+// same update as made by [PostService.Update], so the current state is saved
+// as a new revision and only creator of the post can restore it.
+// Only [ErrInternal], [ErrNotCreator] or [ErrNotFound] can be returned as error
+func (p *PostService) RestoreRevision(
+	ctx context.Context,
+	userId int,
+	postId int,
+	revision int,
+) error {
+	const op = "post-service.RestoreRevision"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting restoring revision",
+		slog.Int("user-id", userId),
+		slog.Int("post-id", postId),
+		slog.Int("revision", revision),
+	)
+	defer log.Info("restoring revision ended")
+
+	var err error
+	sendErr := func(err error) error {
+		return errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to restore - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	rev, err := p.GetRevision(ctx, models.Viewer{Id: userId}, postId, revision)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	_, err = p.Update(
+		ctx,
+		userId,
+		postId,
+		rev.Header,
+		rev.Content,
+		rev.ContentFormat,
+		rev.Themes,
+		models.FullUpdateMask(),
+		nil,
+		nil,
+		false,
+		0,
+	)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// revision returns the revision of the post. Zero revision is
+// the current state of the post read by the viewer.
+// Only [ErrInternal] or [ErrNotFound] can be returned as error
+func (p *PostService) revision(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	revision int,
+) (models.Revision, error) {
+	const op = "post-service.revision"
+	log := p.log.With(slog.String("op", op))
+
+	var (
+		rev models.Revision
+		err error
+	)
+	if revision == 0 {
+		var post models.Post
+		post, err = p.prvdr.Post(ctx, viewer, postId)
+		rev = models.Revision{
+			PostId:        post.Id,
+			Header:        post.Header,
+			Content:       post.Content,
+			Themes:        post.Themes,
+			ContentFormat: post.ContentFormat,
+		}
+	} else {
+		rev, err = p.rvsnPrvdr.Revision(ctx, postId, revision)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn(
+				"revision is not found",
+				slog.Int("post-id", postId),
+				slog.Int("revision", revision),
+				sl.Err(err),
+			)
+			return models.Revision{}, errs.Fail(op, ErrNotFound)
+		}
+
+		log.Error("failed to get revision", sl.Err(err))
+		return models.Revision{}, errs.Fail(op, ErrInternal)
+	}
+
+	return rev, nil
+}
@@ -0,0 +1,117 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/paging"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+// RestorePost takes the deleted post out of trash. Only creator of the post
+// can restore it.
+// Only [ErrInternal], [ErrNotCreator] or [ErrNotFound] can be returned as error
+func (p *PostService) RestorePost(
+	ctx context.Context,
+	postId int,
+	userId int,
+) error {
+	const op = "post-service.RestorePost"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting restoring post",
+		slog.Int("post-id", postId),
+		slog.Int("user-id", userId),
+	)
+	defer log.Info("restoring post ended")
+
+	var err error
+	sendErr := func(err error) error {
+		return errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to restore - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	err = p.dltr.RestorePost(ctx, postId, userId)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("post is not found in trash", slog.Int("post-id", postId), sl.Err(err))
+			return sendErr(ErrNotFound)
+		}
+		if errors.Is(err, storage.ErrNotCreator) {
+			log.Warn(
+				"user is not creator of the post",
+				slog.Int("post-id", postId),
+				slog.Int("user-id", userId),
+				sl.Err(err),
+			)
+			return sendErr(ErrNotCreator)
+		}
+
+		log.Error("failed to restore post", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return nil
+}
+
+// ListTrash returns page of users' deleted posts, the most recently deleted
+// first, and token of the next page.
+// Only [ErrInternal] or [ErrInvalidToken] can be returned as error
+func (p *PostService) ListTrash(
+	ctx context.Context,
+	userId int,
+	pageToken string,
+	pageSize int,
+) ([]models.Post, string, error) {
+	const op = "post-service.ListTrash"
+	log := p.log.With(slog.String("op", op))
+	log.Info(
+		"starting listing trash",
+		slog.Int("user-id", userId),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("listing trash ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Post, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	after, err := cursor.Decode(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, p.timeout)
+	defer cncl()
+
+	limit := paging.Limit(pageSize)
+	posts, err := p.prvdr.Trash(ctx, userId, after, limit+1)
+	if err != nil {
+		log.Error("failed to list trash", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	posts, nextToken := paging.Cut(posts, limit, trashToken)
+
+	return posts, nextToken, nil
+}
@@ -0,0 +1,166 @@
+package purger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/periodic"
+)
+
+type TrashPurger interface {
+	PurgeTrash(ctx context.Context, before time.Time, limit int) (int, error)
+	PurgeIdempotencyKeys(ctx context.Context, before time.Time, limit int) (int, error)
+	// PurgeAttachments returns blob keys of purged attachments
+	PurgeAttachments(ctx context.Context, limit int) ([]string, error)
+}
+
+type BlobDeleter interface {
+	Delete(ctx context.Context, key string) error
+}
+
+// Worker periodically deletes posts which have been in trash for longer
+// than the retention period with their attachments and idempotency keys
+// which have expired
+type Worker struct {
+	log            *slog.Logger
+	purger         TrashPurger
+	blobs          BlobDeleter
+	runner         *periodic.Runner
+	timeout        time.Duration
+	retention      time.Duration
+	idempotencyTTL time.Duration
+	batchSize      int
+}
+
+func New(
+	log *slog.Logger,
+	purger TrashPurger,
+	blobs BlobDeleter,
+	interval time.Duration,
+	retention time.Duration,
+	idempotencyTTL time.Duration,
+	batchSize int,
+) *Worker {
+	return &Worker{
+		log:            log,
+		purger:         purger,
+		blobs:          blobs,
+		timeout:        interval,
+		retention:      retention,
+		idempotencyTTL: idempotencyTTL,
+		batchSize:      batchSize,
+		runner:         periodic.New(interval),
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	const op = "purger.Start"
+	log := w.log.With(slog.String("op", op))
+
+	w.runner.Start(func() {
+		if err := w.purge(); err != nil {
+			log.Error("failed to purge", sl.Err(err))
+		}
+	})
+
+	return nil
+}
+
+func (w *Worker) Stop() {
+	const op = "purger.Stop"
+	w.log.Info("starting to stop worker", slog.String("op", op))
+
+	w.runner.Stop()
+}
+
+// purge deletes expired posts, attachments of deleted posts
+// and expired idempotency keys
+func (w *Worker) purge() error {
+	const op = "purger.purge"
+	log := w.log.With(slog.String("op", op))
+
+	ctx, cncl := context.WithTimeout(context.Background(), w.timeout)
+	defer cncl()
+
+	now := time.Now()
+
+	total, err := w.purgeBatches(ctx, now.Add(-w.retention), w.purger.PurgeTrash)
+	if err != nil {
+		return fail(op, err)
+	}
+	if total > 0 {
+		log.Info("trash is purged", slog.Int("posts", total))
+	}
+
+	total, err = w.purgeBatches(ctx, now, w.purgeAttachments)
+	if err != nil {
+		return fail(op, err)
+	}
+	if total > 0 {
+		log.Info("attachments are purged", slog.Int("attachments", total))
+	}
+
+	total, err = w.purgeBatches(ctx, now.Add(-w.idempotencyTTL), w.purger.PurgeIdempotencyKeys)
+	if err != nil {
+		return fail(op, err)
+	}
+	if total > 0 {
+		log.Info("idempotency keys are purged", slog.Int("keys", total))
+	}
+
+	return nil
+}
+
+// purgeAttachments deletes at most limit attachments of purged posts and
+// their blobs. Blob which is failed to be deleted is left in the blob store
+func (w *Worker) purgeAttachments(ctx context.Context, _ time.Time, limit int) (int, error) {
+	const op = "purger.purgeAttachments"
+	log := w.log.With(slog.String("op", op))
+
+	keys, err := w.purger.PurgeAttachments(ctx, limit)
+	if err != nil {
+		return 0, fail(op, err)
+	}
+
+	for _, key := range keys {
+		if err = w.blobs.Delete(ctx, key); err != nil {
+			log.Error("failed to delete blob", slog.String("key", key), sl.Err(err))
+		}
+	}
+
+	return len(keys), nil
+}
+
+// purgeBatches calls purgeFn batch by batch until a batch is not full
+// or the worker is stopped. Returns total number of purged records
+func (w *Worker) purgeBatches(
+	ctx context.Context,
+	before time.Time,
+	purgeFn func(ctx context.Context, before time.Time, limit int) (int, error),
+) (int, error) {
+	total := 0
+	for {
+		n, err := purgeFn(ctx, before, w.batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += n
+
+		if n < w.batchSize {
+			return total, nil
+		}
+
+		select {
+		case <-w.runner.Done():
+			return total, nil
+		default:
+		}
+	}
+}
+
+func fail(op string, err error) error {
+	return fmt.Errorf("%s: %w", op, err)
+}
@@ -0,0 +1,15 @@
+package reactions
+
+import (
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/service/access"
+)
+
+var (
+	ErrInternal     = access.ErrInternal
+	ErrNotFound     = access.ErrNotFound
+	ErrUserNotFound = access.ErrUserNotFound
+	ErrInvalidKind  = errors.New("unknown reaction kind")
+	ErrInvalidToken = errors.New("invalid page token")
+)
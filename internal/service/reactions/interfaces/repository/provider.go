@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+)
+
+type Provider interface {
+	// Post returns the post if the viewer can read it.
+	// Return values: post, error
+	Post(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+	) (models.Post, error)
+
+	// Reactors returns at most limit reactions to the post after the cursor,
+	// newest first. Return values: reactions, error
+	Reactors(
+		ctx context.Context,
+		postId int,
+		kind string,
+		after cursor.Cursor,
+		limit int,
+	) ([]models.Reaction, error)
+
+	// ReactionCounts returns counters of reactions to the post.
+	// Return values: counters, error
+	ReactionCounts(
+		ctx context.Context,
+		postId int,
+	) ([]models.ReactionCount, error)
+}
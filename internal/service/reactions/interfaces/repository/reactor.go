@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+)
+
+type Reactor interface {
+	// React sets reaction of the user to the post replacing the previous one.
+	// Return values: counters of reactions to the post, error
+	React(
+		ctx context.Context,
+		postId int,
+		userId int,
+		kind string,
+	) ([]models.ReactionCount, error)
+
+	// Unreact removes reaction of the user to the post.
+	// Return values: counters of reactions to the post, error
+	Unreact(
+		ctx context.Context,
+		postId int,
+		userId int,
+	) ([]models.ReactionCount, error)
+}
@@ -0,0 +1,223 @@
+package reactions
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/paging"
+	"github.com/IlianBuh/Post-service/internal/service/access"
+	extraresources "github.com/IlianBuh/Post-service/internal/service/reactions/interfaces/extra-resources"
+	"github.com/IlianBuh/Post-service/internal/service/reactions/interfaces/repository"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+type ReactionService struct {
+	log      *slog.Logger
+	rctr     repository.Reactor
+	prvdr    repository.Provider
+	usrPrvdr extraresources.UserProvider
+	kinds    map[string]struct{}
+	timeout  time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	rctr repository.Reactor,
+	prvdr repository.Provider,
+	usrPrvdr extraresources.UserProvider,
+	kinds []string,
+	timeout time.Duration,
+) *ReactionService {
+	kindSet := make(map[string]struct{}, len(kinds))
+	for _, kind := range kinds {
+		kindSet[kind] = struct{}{}
+	}
+
+	return &ReactionService{
+		log:      log,
+		rctr:     rctr,
+		prvdr:    prvdr,
+		usrPrvdr: usrPrvdr,
+		kinds:    kindSet,
+		timeout:  timeout,
+	}
+}
+
+// React sets reaction of the kind of the viewer to the published post, the
+// previous reaction of the viewer is replaced. The viewer must exist and be
+// able to read the post. Returns counters of reactions to the post.
+// Only [ErrInternal], [ErrNotFound], [ErrUserNotFound] or [ErrInvalidKind]
+// can be returned as error
+func (r *ReactionService) React(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	kind string,
+) ([]models.ReactionCount, error) {
+	const op = "reaction-service.React"
+	log := r.log.With(slog.String("op", op))
+	log.Info(
+		"starting reacting to post",
+		slog.Int("user-id", viewer.Id),
+		slog.Int("post-id", postId),
+		slog.String("kind", kind),
+	)
+	defer log.Info("reacting to post ended")
+
+	var err error
+	sendErr := func(err error) ([]models.ReactionCount, error) {
+		return nil, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to react - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	if _, ok := r.kinds[kind]; !ok {
+		log.Warn("unknown reaction kind", slog.String("kind", kind))
+		return sendErr(ErrInvalidKind)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, r.timeout)
+	defer cncl()
+
+	if err = access.CheckUserExisting(ctx, log, r.usrPrvdr, viewer.Id); err != nil {
+		return sendErr(err)
+	}
+
+	if err = access.CheckPostReadable(ctx, log, r.prvdr, viewer, postId); err != nil {
+		return sendErr(err)
+	}
+
+	counts, err := r.rctr.React(ctx, postId, viewer.Id, kind)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("post is not published", slog.Int("post-id", postId), sl.Err(err))
+			return sendErr(ErrNotFound)
+		}
+
+		log.Error("failed to react to post", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return counts, nil
+}
+
+// Unreact removes reaction of the user to the post. Removing missing
+// reaction is not an error. Returns counters of reactions to the post.
+// Only [ErrInternal] can be returned as error
+func (r *ReactionService) Unreact(
+	ctx context.Context,
+	userId int,
+	postId int,
+) ([]models.ReactionCount, error) {
+	const op = "reaction-service.Unreact"
+	log := r.log.With(slog.String("op", op))
+	log.Info(
+		"starting removing reaction",
+		slog.Int("user-id", userId),
+		slog.Int("post-id", postId),
+	)
+	defer log.Info("removing reaction ended")
+
+	var err error
+	sendErr := func(err error) ([]models.ReactionCount, error) {
+		return nil, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to unreact - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, r.timeout)
+	defer cncl()
+
+	counts, err := r.rctr.Unreact(ctx, postId, userId)
+	if err != nil {
+		log.Error("failed to remove reaction", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return counts, nil
+}
+
+// ListReactors returns page of reactions to the post, newest first, counters
+// of reactions to the post and token of the next page. Empty kind means
+// reactions of any kind. The viewer must be able to read the post.
+// Only [ErrInternal], [ErrNotFound], [ErrInvalidKind] or [ErrInvalidToken]
+// can be returned as error
+func (r *ReactionService) ListReactors(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+	kind string,
+	pageToken string,
+	pageSize int,
+) ([]models.Reaction, []models.ReactionCount, string, error) {
+	const op = "reaction-service.ListReactors"
+	log := r.log.With(slog.String("op", op))
+	log.Info(
+		"starting listing reactors",
+		slog.Int("viewer-id", viewer.Id),
+		slog.Int("post-id", postId),
+		slog.String("kind", kind),
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("listing reactors ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Reaction, []models.ReactionCount, string, error) {
+		return nil, nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	if _, ok := r.kinds[kind]; kind != "" && !ok {
+		log.Warn("unknown reaction kind", slog.String("kind", kind))
+		return sendErr(ErrInvalidKind)
+	}
+
+	after, err := cursor.Decode(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, r.timeout)
+	defer cncl()
+
+	if err = access.CheckPostReadable(ctx, log, r.prvdr, viewer, postId); err != nil {
+		return sendErr(err)
+	}
+
+	limit := paging.Limit(pageSize)
+	reactions, err := r.prvdr.Reactors(ctx, postId, kind, after, limit+1)
+	if err != nil {
+		log.Error("failed to list reactors", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	counts, err := r.prvdr.ReactionCounts(ctx, postId)
+	if err != nil {
+		log.Error("failed to get reaction counts", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	reactions, nextToken := paging.Cut(reactions, limit, func(last models.Reaction) string {
+		return cursor.Encode(cursor.Cursor{CreatedAt: last.ReactedAt, PostId: last.UserId})
+	})
+
+	return reactions, counts, nextToken, nil
+}
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/periodic"
+)
+
+type Publisher interface {
+	PublishDue(ctx context.Context, limit int) (int, error)
+}
+
+// Worker periodically publishes scheduled posts
+// which publishing time has come
+type Worker struct {
+	log       *slog.Logger
+	publisher Publisher
+	runner    *periodic.Runner
+	timeout   time.Duration
+	batchSize int
+}
+
+func New(
+	log *slog.Logger,
+	publisher Publisher,
+	interval time.Duration,
+	batchSize int,
+) *Worker {
+	return &Worker{
+		log:       log,
+		publisher: publisher,
+		timeout:   interval,
+		batchSize: batchSize,
+		runner:    periodic.New(interval),
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	const op = "scheduler.Start"
+	log := w.log.With(slog.String("op", op))
+
+	w.runner.Start(func() {
+		if err := w.publish(); err != nil {
+			log.Error("failed to publish scheduled posts", sl.Err(err))
+		}
+	})
+
+	return nil
+}
+
+func (w *Worker) Stop() {
+	const op = "scheduler.Stop"
+	w.log.Info("starting to stop worker", slog.String("op", op))
+
+	w.runner.Stop()
+}
+
+// publish publishes due posts batch by batch until a batch is not full
+// or the worker is stopped
+func (w *Worker) publish() error {
+	const op = "scheduler.publish"
+	log := w.log.With(slog.String("op", op))
+
+	ctx, cncl := context.WithTimeout(context.Background(), w.timeout)
+	defer cncl()
+
+	total := 0
+	for {
+		n, err := w.publisher.PublishDue(ctx, w.batchSize)
+		if err != nil {
+			return fail(op, err)
+		}
+		total += n
+
+		if n < w.batchSize {
+			break
+		}
+
+		select {
+		case <-w.runner.Done():
+			return nil
+		default:
+		}
+	}
+
+	if total > 0 {
+		log.Info("scheduled posts are published", slog.Int("posts", total))
+	}
+
+	return nil
+}
+
+func fail(op string, err error) error {
+	return fmt.Errorf("%s: %w", op, err)
+}
@@ -0,0 +1,15 @@
+package themes
+
+import (
+	"errors"
+)
+
+var (
+	ErrInternal      = errors.New("internal error")
+	ErrInvalidToken  = errors.New("invalid page token")
+	ErrForbidden     = errors.New("user is not admin")
+	ErrNotFound      = errors.New("theme not found")
+	ErrThemeExists   = errors.New("theme already exists")
+	ErrInvalidTheme  = errors.New("invalid theme")
+	ErrInvalidWindow = errors.New("invalid window")
+)
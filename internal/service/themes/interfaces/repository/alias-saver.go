@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+)
+
+type AliasSaver interface {
+	// SaveAlias makes alias a synonym of the theme. Return values: error
+	SaveAlias(
+		ctx context.Context,
+		alias string,
+		theme string,
+	) error
+}
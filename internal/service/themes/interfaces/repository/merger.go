@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+)
+
+type Merger interface {
+	// MergeThemes moves posts of source themes to the target one and makes
+	// sources its' aliases. Return values: error
+	MergeThemes(
+		ctx context.Context,
+		sources []string,
+		target string,
+	) error
+}
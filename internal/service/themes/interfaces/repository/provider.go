@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+)
+
+type Provider interface {
+	// Themes returns at most limit themes, the most used first, that are
+	// placed after the cursor. Return values: themes, error
+	Themes(
+		ctx context.Context,
+		after cursor.Count,
+		limit int,
+	) ([]models.Theme, error)
+
+	// ThemesByPrefix returns at most limit themes which names start with
+	// the prefix, the most used first. Return values: themes, error
+	ThemesByPrefix(
+		ctx context.Context,
+		prefix string,
+		limit int,
+	) ([]models.Theme, error)
+
+	// TrendingThemes returns at most limit themes ranked by number of posts
+	// within the window compared with baselineWindows preceding windows.
+	// Return values: themes, error
+	TrendingThemes(
+		ctx context.Context,
+		window time.Duration,
+		baselineWindows int,
+		limit int,
+	) ([]models.TrendingTheme, error)
+}
@@ -0,0 +1,344 @@
+package themes
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/normalize"
+	"github.com/IlianBuh/Post-service/internal/lib/paging"
+	"github.com/IlianBuh/Post-service/internal/service/themes/interfaces/repository"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 20
+
+	defaultTrendingWindow = 24 * time.Hour
+	minTrendingWindow     = time.Hour
+	// maxTrendingWindow together with its' baseline must fit
+	// into 30 days of aggregated statistics
+	maxTrendingWindow    = 72 * time.Hour
+	baselineWindows      = 7
+	defaultTrendingLimit = 10
+	maxTrendingLimit     = 50
+)
+
+type ThemeService struct {
+	log      *slog.Logger
+	prvdr    repository.Provider
+	mrgr     repository.Merger
+	aliasSvr repository.AliasSaver
+	admins   map[int]struct{}
+	timeout  time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	prvdr repository.Provider,
+	mrgr repository.Merger,
+	aliasSvr repository.AliasSaver,
+	admins []int,
+	timeout time.Duration,
+) *ThemeService {
+	adminSet := make(map[int]struct{}, len(admins))
+	for _, id := range admins {
+		adminSet[id] = struct{}{}
+	}
+
+	return &ThemeService{
+		log:      log,
+		prvdr:    prvdr,
+		mrgr:     mrgr,
+		aliasSvr: aliasSvr,
+		admins:   adminSet,
+		timeout:  timeout,
+	}
+}
+
+// List returns page of themes, the most used first, and token of the next page.
+// Empty pageToken means the first page, empty returned token means there are
+// no more themes.
+// Only [ErrInternal] or [ErrInvalidToken] can be returned as error
+func (t *ThemeService) List(
+	ctx context.Context,
+	pageToken string,
+	pageSize int,
+) ([]models.Theme, string, error) {
+	const op = "theme-service.List"
+	log := t.log.With(slog.String("op", op))
+	log.Info(
+		"starting listing themes",
+		slog.String("page-token", pageToken),
+		slog.Int("page-size", pageSize),
+	)
+	defer log.Info("listing themes ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Theme, string, error) {
+		return nil, "", errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to list - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	after, err := cursor.DecodeCount(pageToken)
+	if err != nil {
+		log.Warn("invalid page token", sl.Err(err))
+		return sendErr(ErrInvalidToken)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, t.timeout)
+	defer cncl()
+
+	limit := paging.Limit(pageSize)
+	themes, err := t.prvdr.Themes(ctx, after, limit+1)
+	if err != nil {
+		log.Error("failed to list themes", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	themes, nextToken := paging.Cut(themes, limit, func(last models.Theme) string {
+		return cursor.EncodeCount(cursor.Count{Count: last.PostsCount, Id: last.Id})
+	})
+
+	return themes, nextToken, nil
+}
+
+// Suggest returns themes which names start with the prefix ignoring case,
+// the most used first.
+// Only [ErrInternal] can be returned as error
+func (t *ThemeService) Suggest(
+	ctx context.Context,
+	prefix string,
+	limit int,
+) ([]models.Theme, error) {
+	const op = "theme-service.Suggest"
+	log := t.log.With(slog.String("op", op))
+	log.Info(
+		"starting suggesting themes",
+		slog.String("prefix", prefix),
+		slog.Int("limit", limit),
+	)
+	defer log.Info("suggesting themes ended")
+
+	var err error
+	sendErr := func(err error) ([]models.Theme, error) {
+		return nil, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to suggest - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, t.timeout)
+	defer cncl()
+
+	themes, err := t.prvdr.ThemesByPrefix(
+		ctx, normalize.Theme(prefix), bound(limit, defaultSuggestLimit, maxSuggestLimit),
+	)
+	if err != nil {
+		log.Error("failed to suggest themes", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return themes, nil
+}
+
+// Trending returns themes which got more posts within the window than
+// usually, the most trending first. Zero window means the last 24 hours.
+// Only [ErrInternal] or [ErrInvalidWindow] can be returned as error
+func (t *ThemeService) Trending(
+	ctx context.Context,
+	window time.Duration,
+	limit int,
+) ([]models.TrendingTheme, error) {
+	const op = "theme-service.Trending"
+	log := t.log.With(slog.String("op", op))
+	log.Info(
+		"starting getting trending themes",
+		slog.Duration("window", window),
+		slog.Int("limit", limit),
+	)
+	defer log.Info("getting trending themes ended")
+
+	var err error
+	sendErr := func(err error) ([]models.TrendingTheme, error) {
+		return nil, errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to get trending - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	if window == 0 {
+		window = defaultTrendingWindow
+	}
+	if window < minTrendingWindow || window > maxTrendingWindow {
+		log.Warn("window is out of range", slog.Duration("window", window))
+		return sendErr(ErrInvalidWindow)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, t.timeout)
+	defer cncl()
+
+	themes, err := t.prvdr.TrendingThemes(
+		ctx, window, baselineWindows, bound(limit, defaultTrendingLimit, maxTrendingLimit),
+	)
+	if err != nil {
+		log.Error("failed to get trending themes", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return themes, nil
+}
+
+// Merge moves all posts of source themes to the target theme and deletes the
+// sources, their names become aliases of the target. Only admins can merge themes.
+// Only [ErrInternal], [ErrForbidden], [ErrInvalidTheme] or [ErrNotFound] can
+// be returned as error
+func (t *ThemeService) Merge(
+	ctx context.Context,
+	userId int,
+	sources []string,
+	target string,
+) error {
+	const op = "theme-service.Merge"
+	log := t.log.With(slog.String("op", op))
+	log.Info(
+		"starting merging themes",
+		slog.Int("user-id", userId),
+		slog.Any("sources", sources),
+		slog.String("target", target),
+	)
+	defer log.Info("merging themes ended")
+
+	var err error
+	sendErr := func(err error) error {
+		return errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to merge - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	if !t.isAdmin(userId) {
+		log.Warn("user is not admin", slog.Int("user-id", userId))
+		return sendErr(ErrForbidden)
+	}
+
+	target = normalize.Theme(target)
+	sources = slices.DeleteFunc(
+		normalize.Themes(sources),
+		func(source string) bool { return source == target },
+	)
+	if target == "" || len(sources) == 0 {
+		log.Warn("nothing to merge", slog.Any("sources", sources), slog.String("target", target))
+		return sendErr(ErrInvalidTheme)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, t.timeout)
+	defer cncl()
+
+	err = t.mrgr.MergeThemes(ctx, sources, target)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Warn("some of source themes are not found", sl.Err(err))
+			return sendErr(ErrNotFound)
+		}
+
+		log.Error("failed to merge themes", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return nil
+}
+
+// AddAlias makes alias a synonym of the theme, so posts created with the alias
+// get the theme. Only admins can add aliases.
+// Only [ErrInternal], [ErrForbidden], [ErrInvalidTheme] or [ErrThemeExists] can
+// be returned as error
+func (t *ThemeService) AddAlias(
+	ctx context.Context,
+	userId int,
+	alias string,
+	theme string,
+) error {
+	const op = "theme-service.AddAlias"
+	log := t.log.With(slog.String("op", op))
+	log.Info(
+		"starting adding alias",
+		slog.Int("user-id", userId),
+		slog.String("alias", alias),
+		slog.String("theme", theme),
+	)
+	defer log.Info("adding alias ended")
+
+	var err error
+	sendErr := func(err error) error {
+		return errs.Fail(op, err)
+	}
+
+	if err = ctx.Err(); err != nil {
+		log.Error("failed to add alias - context is canceled", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	if !t.isAdmin(userId) {
+		log.Warn("user is not admin", slog.Int("user-id", userId))
+		return sendErr(ErrForbidden)
+	}
+
+	alias, theme = normalize.Theme(alias), normalize.Theme(theme)
+	if alias == "" || theme == "" || alias == theme {
+		log.Warn("invalid alias", slog.String("alias", alias), slog.String("theme", theme))
+		return sendErr(ErrInvalidTheme)
+	}
+
+	ctx, cncl := context.WithTimeout(ctx, t.timeout)
+	defer cncl()
+
+	err = t.aliasSvr.SaveAlias(ctx, alias, theme)
+	if err != nil {
+		if errors.Is(err, storage.ErrThemeExists) {
+			log.Warn("theme with the alias name exists", sl.Err(err))
+			return sendErr(ErrThemeExists)
+		}
+
+		log.Error("failed to save alias", sl.Err(err))
+		return sendErr(ErrInternal)
+	}
+
+	return nil
+}
+
+// isAdmin checks if the user is allowed to manage themes
+func (t *ThemeService) isAdmin(userId int) bool {
+	_, ok := t.admins[userId]
+	return ok
+}
+
+// bound returns value bounded by [1, max].
+// Non-positive value is replaced with def
+func bound(value, def, max int) int {
+	switch {
+	case value <= 0:
+		return def
+	case value > max:
+		return max
+	}
+
+	return value
+}
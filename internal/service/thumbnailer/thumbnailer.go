@@ -0,0 +1,301 @@
+package thumbnailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/imaging"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/periodic"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+type Repository interface {
+	PendingImages(ctx context.Context, limit int) ([]models.Attachment, error)
+	// SaveImageVariants returns [storage.ErrNotFound]
+	// if the image is not pending anymore
+	SaveImageVariants(ctx context.Context, att models.Attachment) error
+	FailImage(ctx context.Context, attachmentId int) error
+	// RetryImage reports whether the image reached maxAttempts
+	// and is marked as failed
+	RetryImage(ctx context.Context, attachmentId int, maxAttempts int) (bool, error)
+}
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Worker periodically processes uploaded images. Metadata of the image
+// is stripped, its' dimensions are saved and thumbnails are generated
+// for every configured size. Image which fails to be processed is retried
+// on the next ticks and marked as failed after maxAttempts
+type Worker struct {
+	log         *slog.Logger
+	repo        Repository
+	blobs       BlobStore
+	runner      *periodic.Runner
+	timeout     time.Duration
+	batchSize   int
+	sizes       []int
+	maxPixels   int
+	maxAttempts int
+}
+
+func New(
+	log *slog.Logger,
+	repo Repository,
+	blobs BlobStore,
+	interval time.Duration,
+	batchSize int,
+	sizes []int,
+	maxPixels int,
+	maxAttempts int,
+) *Worker {
+	return &Worker{
+		log:         log,
+		repo:        repo,
+		blobs:       blobs,
+		timeout:     interval,
+		batchSize:   batchSize,
+		sizes:       sizes,
+		maxPixels:   maxPixels,
+		maxAttempts: maxAttempts,
+		runner:      periodic.New(interval),
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	const op = "thumbnailer.Start"
+	log := w.log.With(slog.String("op", op))
+
+	w.runner.Start(func() {
+		if err := w.processPending(); err != nil {
+			log.Error("failed to process images", sl.Err(err))
+		}
+	})
+
+	return nil
+}
+
+func (w *Worker) Stop() {
+	const op = "thumbnailer.Stop"
+	w.log.Info("starting to stop worker", slog.String("op", op))
+
+	w.runner.Stop()
+}
+
+// processPending processes one batch of pending images. Image which
+// failed to be processed is left pending and retried on the next tick
+// until it runs out of attempts
+func (w *Worker) processPending() error {
+	const op = "thumbnailer.processPending"
+	log := w.log.With(slog.String("op", op))
+
+	ctx, cncl := context.WithTimeout(context.Background(), w.timeout)
+	atts, err := w.repo.PendingImages(ctx, w.batchSize)
+	cncl()
+	if err != nil {
+		return fail(op, err)
+	}
+
+	done := 0
+	for _, att := range atts {
+		select {
+		case <-w.runner.Done():
+			return nil
+		default:
+		}
+
+		if err = w.process(att); err != nil {
+			log.Error("failed to process image", slog.Int("attachment-id", att.Id), sl.Err(err))
+			w.retry(att)
+			continue
+		}
+		done++
+	}
+
+	if done > 0 {
+		log.Info("images are processed", slog.Int("images", done))
+	}
+
+	return nil
+}
+
+// process strips metadata of the image and generates its' thumbnails within
+// the timeout. Image which can't be decoded is marked as failed
+func (w *Worker) process(att models.Attachment) error {
+	const op = "thumbnailer.process"
+	log := w.log.With(slog.String("op", op), slog.Int("attachment-id", att.Id))
+
+	ctx, cncl := context.WithTimeout(context.Background(), w.timeout)
+	defer cncl()
+
+	data, err := w.read(ctx, att.BlobKey)
+	if err != nil {
+		return fail(op, err)
+	}
+
+	img, err := imaging.Decode(data, w.maxPixels)
+	if err != nil {
+		log.Warn("failed to decode image", sl.Err(err))
+		return w.markFailed(ctx, att)
+	}
+
+	clean, err := imaging.StripMetadata(data, att.MimeType)
+	if err != nil {
+		log.Warn("failed to strip metadata", sl.Err(err))
+		return w.markFailed(ctx, att)
+	}
+
+	// blobs put by this call are deleted if the image can't be saved
+	var putKeys []string
+	cleanUp := func() {
+		for _, key := range putKeys {
+			w.deleteBlob(key)
+		}
+	}
+
+	oldKey := att.BlobKey
+	if len(clean) != len(data) {
+		key := oldKey + "-clean"
+		if _, err = w.blobs.Put(ctx, key, bytes.NewReader(clean)); err != nil {
+			return fail(op, err)
+		}
+		putKeys = append(putKeys, key)
+
+		hash := sha256.Sum256(clean)
+		att.BlobKey = key
+		att.Size = int64(len(clean))
+		att.Sha256 = hex.EncodeToString(hash[:])
+	}
+
+	att.Width, att.Height = img.Bounds().Dx(), img.Bounds().Dy()
+	att.Variants = make([]models.ImageVariant, 0, len(w.sizes))
+	for _, size := range w.sizes {
+		v, err := w.thumbnail(ctx, img, oldKey, att.MimeType, size)
+		if err != nil {
+			cleanUp()
+			return fail(op, err)
+		}
+		putKeys = append(putKeys, v.BlobKey)
+
+		att.Variants = append(att.Variants, v)
+	}
+
+	if err = w.repo.SaveImageVariants(ctx, att); err != nil {
+		cleanUp()
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Info("image is not pending anymore")
+			return nil
+		}
+
+		return fail(op, err)
+	}
+
+	if att.BlobKey != oldKey {
+		w.deleteBlob(oldKey)
+	}
+
+	return nil
+}
+
+// thumbnail generates the thumbnail of the image fitting
+// into the size x size box and puts it into the blob store
+func (w *Worker) thumbnail(
+	ctx context.Context,
+	img image.Image,
+	key string,
+	mimeType string,
+	size int,
+) (models.ImageVariant, error) {
+	thumb := imaging.Thumbnail(img, size)
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, thumb, mimeType); err != nil {
+		return models.ImageVariant{}, err
+	}
+
+	v := models.ImageVariant{
+		Name:     VariantName(size),
+		Width:    thumb.Bounds().Dx(),
+		Height:   thumb.Bounds().Dy(),
+		Size:     int64(buf.Len()),
+		MimeType: imaging.VariantMimeType(mimeType),
+		BlobKey:  fmt.Sprintf("%s-%s", key, VariantName(size)),
+	}
+
+	if _, err := w.blobs.Put(ctx, v.BlobKey, &buf); err != nil {
+		return models.ImageVariant{}, err
+	}
+
+	return v, nil
+}
+
+// retry counts failed attempt to process the image, failure is only logged.
+// It is called on failures, so the context of the image may be done
+func (w *Worker) retry(att models.Attachment) {
+	ctx, cncl := context.WithTimeout(context.Background(), w.timeout)
+	defer cncl()
+
+	failed, err := w.repo.RetryImage(ctx, att.Id, w.maxAttempts)
+	if err != nil {
+		w.log.Error("failed to count attempt", slog.Int("attachment-id", att.Id), sl.Err(err))
+		return
+	}
+	if failed {
+		w.log.Warn("image is out of attempts", slog.Int("attachment-id", att.Id))
+	}
+}
+
+// markFailed marks the image as failed, its' content is left as is
+func (w *Worker) markFailed(ctx context.Context, att models.Attachment) error {
+	const op = "thumbnailer.markFailed"
+
+	if err := w.repo.FailImage(ctx, att.Id); err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// read reads whole content of the blob
+func (w *Worker) read(ctx context.Context, key string) ([]byte, error) {
+	rc, err := w.blobs.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+// deleteBlob deletes the blob, failure is only logged.
+// It is called on failures, so the context of the image may be done
+func (w *Worker) deleteBlob(key string) {
+	ctx, cncl := context.WithTimeout(context.Background(), w.timeout)
+	defer cncl()
+
+	if err := w.blobs.Delete(ctx, key); err != nil {
+		w.log.Error("failed to delete blob", slog.String("key", key), sl.Err(err))
+	}
+}
+
+// VariantName returns name of the thumbnail fitting into the size x size box
+func VariantName(size int) string {
+	return fmt.Sprintf("thumb-%d", size)
+}
+
+func fail(op string, err error) error {
+	return fmt.Errorf("%s: %w", op, err)
+}
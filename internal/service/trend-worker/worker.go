@@ -0,0 +1,74 @@
+package trendworker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/periodic"
+)
+
+type Refresher interface {
+	RefreshThemeStats(ctx context.Context) error
+}
+
+// Worker periodically refreshes aggregated statistics
+// used to find trending themes
+type Worker struct {
+	log       *slog.Logger
+	refresher Refresher
+	runner    *periodic.Runner
+	timeout   time.Duration
+}
+
+func New(
+	log *slog.Logger,
+	refresher Refresher,
+	interval time.Duration,
+) *Worker {
+	return &Worker{
+		log:       log,
+		refresher: refresher,
+		timeout:   interval,
+		runner:    periodic.New(interval),
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	const op = "trendworker.Start"
+	log := w.log.With(slog.String("op", op))
+
+	w.runner.Start(func() {
+		if err := w.refresh(); err != nil {
+			log.Error("failed to refresh theme statistics", sl.Err(err))
+		}
+	})
+
+	return nil
+}
+
+func (w *Worker) Stop() {
+	const op = "trendworker.Stop"
+	w.log.Info("starting to stop worker", slog.String("op", op))
+
+	w.runner.Stop()
+}
+
+func (w *Worker) refresh() error {
+	const op = "trendworker.refresh"
+
+	ctx, cncl := context.WithTimeout(context.Background(), w.timeout)
+	defer cncl()
+
+	if err := w.refresher.RefreshThemeStats(ctx); err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+func fail(op string, err error) error {
+	return fmt.Errorf("%s: %w", op, err)
+}
@@ -0,0 +1,9 @@
+package views
+
+import (
+	"errors"
+)
+
+var (
+	ErrInternal = errors.New("internal error")
+)
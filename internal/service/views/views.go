@@ -0,0 +1,218 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	errs "github.com/IlianBuh/Post-service/internal/lib/errors"
+	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
+	"github.com/IlianBuh/Post-service/internal/lib/periodic"
+)
+
+type Saver interface {
+	// AddViews adds views to stats of the posts. views maps
+	// post id to number of new views
+	AddViews(ctx context.Context, views map[int]int) error
+}
+
+// maxSeen limits number of viewers remembered for deduplication. Viewers
+// whose window has passed are forgotten on flush, until then new viewers
+// above the limit are counted without being remembered
+const maxSeen = 100_000
+
+// viewKey identifies the viewer of the post
+type viewKey struct {
+	postId   int
+	viewerId int
+}
+
+// Counter aggregates views of posts in memory and periodically flushes them
+// to the storage in batches. Views which failed to be flushed are kept until
+// the next flush, pending views are flushed when the counter is stopped
+type Counter struct {
+	log   *slog.Logger
+	saver Saver
+
+	mu      sync.Mutex
+	pending map[int]int
+	// seen keeps the time the viewer was counted for the post,
+	// it is used only if dedupWindow is positive
+	seen        map[viewKey]time.Time
+	dedupWindow time.Duration
+
+	runner    *periodic.Runner
+	timeout   time.Duration
+	batchSize int
+}
+
+func New(
+	log *slog.Logger,
+	saver Saver,
+	interval time.Duration,
+	batchSize int,
+	dedupWindow time.Duration,
+) *Counter {
+	return &Counter{
+		log:         log,
+		saver:       saver,
+		pending:     make(map[int]int),
+		seen:        make(map[viewKey]time.Time),
+		dedupWindow: dedupWindow,
+		timeout:     interval,
+		batchSize:   batchSize,
+		runner:      periodic.New(interval),
+	}
+}
+
+// RecordView counts the view of the post by the viewer. If deduplication is
+// enabled, repeated views of the viewer within the window are not counted.
+// Views of anonymous viewer (zero id) are never deduplicated.
+// Existence of the post is not checked, views of missing posts are dropped
+// by the storage.
+// Only [ErrInternal] can be returned as error
+func (c *Counter) RecordView(
+	ctx context.Context,
+	postId int,
+	viewerId int,
+) error {
+	const op = "views.RecordView"
+
+	if err := ctx.Err(); err != nil {
+		c.log.Error(
+			"failed to record view - context is canceled",
+			slog.String("op", op),
+			sl.Err(err),
+		)
+		return errs.Fail(op, ErrInternal)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dedupWindow > 0 && viewerId != 0 {
+		key := viewKey{postId: postId, viewerId: viewerId}
+		now := time.Now()
+		countedAt, ok := c.seen[key]
+		if ok && now.Sub(countedAt) < c.dedupWindow {
+			return nil
+		}
+
+		if ok || len(c.seen) < maxSeen {
+			c.seen[key] = now
+		}
+	}
+
+	c.pending[postId]++
+
+	return nil
+}
+
+func (c *Counter) Start(ctx context.Context) error {
+	const op = "views.Start"
+	log := c.log.With(slog.String("op", op))
+
+	c.runner.Start(func() {
+		if err := c.flush(); err != nil {
+			log.Error("failed to flush views", sl.Err(err))
+		}
+	})
+
+	return nil
+}
+
+// Stop stops the counter and flushes pending views. Views must not be
+// recorded after the counter is stopped
+func (c *Counter) Stop() {
+	const op = "views.Stop"
+	log := c.log.With(slog.String("op", op))
+	log.Info("starting to stop counter")
+
+	c.runner.Stop()
+
+	if err := c.flush(); err != nil {
+		log.Error("failed to flush pending views", sl.Err(err))
+	}
+}
+
+// flush saves pending views batch by batch. On failure views which
+// are not saved yet are returned to the pending ones
+func (c *Counter) flush() error {
+	const op = "views.flush"
+	log := c.log.With(slog.String("op", op))
+
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = make(map[int]int, len(pending))
+	c.forgetViewers(time.Now())
+	c.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	ctx, cncl := context.WithTimeout(context.Background(), c.timeout)
+	defer cncl()
+
+	total := len(pending)
+	batch := make(map[int]int, c.batchSize)
+	save := func() error {
+		if err := c.saver.AddViews(ctx, batch); err != nil {
+			c.restore(pending)
+			return fail(op, err)
+		}
+
+		for postId := range batch {
+			delete(pending, postId)
+		}
+		clear(batch)
+
+		return nil
+	}
+
+	for postId, n := range pending {
+		batch[postId] = n
+		if len(batch) < c.batchSize {
+			continue
+		}
+
+		if err := save(); err != nil {
+			return err
+		}
+	}
+	if len(batch) != 0 {
+		if err := save(); err != nil {
+			return err
+		}
+	}
+
+	log.Info("views are flushed", slog.Int("posts", total))
+
+	return nil
+}
+
+// restore returns views which failed to be flushed to the pending ones
+func (c *Counter) restore(views map[int]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for postId, n := range views {
+		c.pending[postId] += n
+	}
+}
+
+// forgetViewers removes viewers whose dedup window has passed.
+// Must be called with the lock held
+func (c *Counter) forgetViewers(now time.Time) {
+	for key, countedAt := range c.seen {
+		if now.Sub(countedAt) >= c.dedupWindow {
+			delete(c.seen, key)
+		}
+	}
+}
+
+func fail(op string, err error) error {
+	return fmt.Errorf("%s: %w", op, err)
+}
@@ -0,0 +1,156 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type saverMock struct {
+	mu    sync.Mutex
+	err   error
+	views map[int]int
+}
+
+func (s *saverMock) AddViews(ctx context.Context, views map[int]int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.views == nil {
+		s.views = make(map[int]int)
+	}
+	for postId, n := range views {
+		s.views[postId] += n
+	}
+
+	return nil
+}
+
+func newCounter(saver Saver, dedupWindow time.Duration) *Counter {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), saver, time.Hour, 2, dedupWindow)
+}
+
+func TestRecordViewDedup(t *testing.T) {
+	c := newCounter(&saverMock{}, time.Hour)
+
+	require.NoError(t, c.RecordView(t.Context(), 1, 10))
+	require.NoError(t, c.RecordView(t.Context(), 1, 10))
+	require.NoError(t, c.RecordView(t.Context(), 1, 11))
+	require.NoError(t, c.RecordView(t.Context(), 2, 10))
+
+	require.Equal(t, map[int]int{1: 2, 2: 1}, c.pending)
+}
+
+func TestRecordViewAfterWindow(t *testing.T) {
+	c := newCounter(&saverMock{}, time.Minute)
+
+	require.NoError(t, c.RecordView(t.Context(), 1, 10))
+	c.seen[viewKey{postId: 1, viewerId: 10}] = time.Now().Add(-time.Minute)
+	require.NoError(t, c.RecordView(t.Context(), 1, 10))
+
+	require.Equal(t, map[int]int{1: 2}, c.pending)
+}
+
+func TestRecordViewWithoutDedup(t *testing.T) {
+	c := newCounter(&saverMock{}, 0)
+
+	require.NoError(t, c.RecordView(t.Context(), 1, 10))
+	require.NoError(t, c.RecordView(t.Context(), 1, 10))
+
+	require.Equal(t, map[int]int{1: 2}, c.pending)
+	require.Empty(t, c.seen)
+}
+
+func TestRecordViewAnonymous(t *testing.T) {
+	c := newCounter(&saverMock{}, time.Hour)
+
+	require.NoError(t, c.RecordView(t.Context(), 1, 0))
+	require.NoError(t, c.RecordView(t.Context(), 1, 0))
+
+	require.Equal(t, map[int]int{1: 2}, c.pending)
+	require.Empty(t, c.seen)
+}
+
+func TestRecordViewMaxSeen(t *testing.T) {
+	c := newCounter(&saverMock{}, time.Hour)
+	now := time.Now()
+	for i := range maxSeen {
+		c.seen[viewKey{postId: 1, viewerId: i + 1}] = now
+	}
+
+	require.NoError(t, c.RecordView(t.Context(), 2, 10))
+	require.NoError(t, c.RecordView(t.Context(), 2, 10))
+
+	require.Equal(t, map[int]int{2: 2}, c.pending)
+	require.Len(t, c.seen, maxSeen)
+}
+
+func TestRecordViewCanceled(t *testing.T) {
+	c := newCounter(&saverMock{}, 0)
+	ctx, cncl := context.WithCancel(t.Context())
+	cncl()
+
+	require.ErrorIs(t, c.RecordView(ctx, 1, 10), ErrInternal)
+	require.Empty(t, c.pending)
+}
+
+func TestFlushForgetsViewers(t *testing.T) {
+	saver := &saverMock{}
+	c := newCounter(saver, time.Minute)
+
+	require.NoError(t, c.RecordView(t.Context(), 1, 10))
+	require.NoError(t, c.RecordView(t.Context(), 1, 11))
+	c.seen[viewKey{postId: 1, viewerId: 10}] = time.Now().Add(-time.Minute)
+
+	require.NoError(t, c.flush())
+
+	require.Equal(t, map[int]int{1: 2}, saver.views)
+	require.Empty(t, c.pending)
+	require.Len(t, c.seen, 1)
+	require.Contains(t, c.seen, viewKey{postId: 1, viewerId: 11})
+}
+
+func TestFlushRestoresFailed(t *testing.T) {
+	saver := &saverMock{err: errors.New("storage is down")}
+	c := newCounter(saver, 0)
+
+	for postId := 1; postId <= 5; postId++ {
+		require.NoError(t, c.RecordView(t.Context(), postId, 0))
+	}
+	want := maps.Clone(c.pending)
+
+	require.Error(t, c.flush())
+	require.Equal(t, want, c.pending)
+
+	require.NoError(t, c.RecordView(t.Context(), 1, 0))
+	want[1]++
+	saver.err = nil
+
+	require.NoError(t, c.flush())
+	require.Equal(t, want, saver.views)
+	require.Empty(t, c.pending)
+}
+
+func TestStopFlushes(t *testing.T) {
+	saver := &saverMock{}
+	c := newCounter(saver, 0)
+	require.NoError(t, c.Start(t.Context()))
+
+	require.NoError(t, c.RecordView(t.Context(), 1, 10))
+	require.NoError(t, c.RecordView(t.Context(), 2, 10))
+	c.Stop()
+
+	require.Equal(t, map[int]int{1: 1, 2: 1}, saver.views)
+	require.Empty(t, c.pending)
+}
@@ -9,13 +9,20 @@ import (
 )
 
 const (
-	TypeCteated = "created"
+	TypeCteated         = "created"
+	TypeThumbnailsReady = "thumbnails_ready"
+	TypeReacted         = "reacted"
+	TypeCommentCreated  = "comment_created"
+	TypeMentioned       = "mentioned"
 )
 
 type EventPayload struct {
-	Author    Author    `json:"author"`
-	Header    string    `json:"header"`
-	CreatedAt time.Time `json:"created-at"`
+	PostId     int       `json:"post-id"`
+	Author     Author    `json:"author"`
+	Header     string    `json:"header"`
+	Excerpt    string    `json:"excerpt"`
+	CreatedAt  time.Time `json:"created-at"`
+	Visibility string    `json:"visibility"`
 }
 
 type Author struct {
@@ -23,17 +30,28 @@ type Author struct {
 	Login string `json:"login"`
 }
 
-func CollectEventPayload(id int, login string, header string, createdAt time.Time) (string, error) {
+func CollectEventPayload(
+	postId int,
+	id int,
+	login string,
+	header string,
+	excerpt string,
+	createdAt time.Time,
+	visibility string,
+) (string, error) {
 	const op = "event.CollectEventPayload"
 
 	payload, err := json.Marshal(
 		EventPayload{
+			postId,
 			Author{
 				Id:    id,
 				Login: login,
 			},
 			header,
+			excerpt,
 			createdAt,
+			visibility,
 		},
 	)
 	if err != nil {
@@ -43,6 +61,187 @@ func CollectEventPayload(id int, login string, header string, createdAt time.Tim
 	return string(payload), nil
 }
 
-func CollectEventId(userId int) string {
-	return fmt.Sprintf(`%d_%d`, userId, time.Now().Unix())
+// ThumbnailsPayload is a payload of the event about processed image attachment
+type ThumbnailsPayload struct {
+	PostId   int      `json:"post-id"`
+	FileName string   `json:"file-name"`
+	Width    int      `json:"width"`
+	Height   int      `json:"height"`
+	Variants []string `json:"variants"`
+}
+
+func CollectThumbnailsPayload(
+	postId int,
+	fileName string,
+	width int,
+	height int,
+	variants []string,
+) (string, error) {
+	const op = "event.CollectThumbnailsPayload"
+
+	payload, err := json.Marshal(
+		ThumbnailsPayload{
+			PostId:   postId,
+			FileName: fileName,
+			Width:    width,
+			Height:   height,
+			Variants: variants,
+		},
+	)
+	if err != nil {
+		return "", e.Fail(op, err)
+	}
+
+	return string(payload), nil
+}
+
+// ReactionPayload is a payload of the event about new reaction to the post
+type ReactionPayload struct {
+	PostId    int       `json:"post-id"`
+	AuthorId  int       `json:"author-id"`
+	UserId    int       `json:"user-id"`
+	Kind      string    `json:"kind"`
+	ReactedAt time.Time `json:"reacted-at"`
+}
+
+func CollectReactionPayload(
+	postId int,
+	authorId int,
+	userId int,
+	kind string,
+	reactedAt time.Time,
+) (string, error) {
+	const op = "event.CollectReactionPayload"
+
+	payload, err := json.Marshal(
+		ReactionPayload{
+			PostId:    postId,
+			AuthorId:  authorId,
+			UserId:    userId,
+			Kind:      kind,
+			ReactedAt: reactedAt,
+		},
+	)
+	if err != nil {
+		return "", e.Fail(op, err)
+	}
+
+	return string(payload), nil
+}
+
+// CommentPayload is a payload of the event about new comment to the post.
+// Parent fields are zero for top-level comments
+type CommentPayload struct {
+	CommentId      int       `json:"comment-id"`
+	PostId         int       `json:"post-id"`
+	PostAuthorId   int       `json:"post-author-id"`
+	ParentId       int       `json:"parent-id"`
+	ParentAuthorId int       `json:"parent-author-id"`
+	UserId         int       `json:"user-id"`
+	CreatedAt      time.Time `json:"created-at"`
+}
+
+func CollectCommentPayload(
+	commentId int,
+	postId int,
+	postAuthorId int,
+	parentId int,
+	parentAuthorId int,
+	userId int,
+	createdAt time.Time,
+) (string, error) {
+	const op = "event.CollectCommentPayload"
+
+	payload, err := json.Marshal(
+		CommentPayload{
+			CommentId:      commentId,
+			PostId:         postId,
+			PostAuthorId:   postAuthorId,
+			ParentId:       parentId,
+			ParentAuthorId: parentAuthorId,
+			UserId:         userId,
+			CreatedAt:      createdAt,
+		},
+	)
+	if err != nil {
+		return "", e.Fail(op, err)
+	}
+
+	return string(payload), nil
+}
+
+// MentionPayload is a payload of the event about the user mentioned in the
+// published post
+type MentionPayload struct {
+	PostId      int       `json:"post-id"`
+	Author      Author    `json:"author"`
+	UserId      int       `json:"user-id"`
+	Login       string    `json:"login"`
+	Header      string    `json:"header"`
+	Visibility  string    `json:"visibility"`
+	MentionedAt time.Time `json:"mentioned-at"`
+}
+
+func CollectMentionPayload(
+	postId int,
+	authorId int,
+	authorLogin string,
+	userId int,
+	login string,
+	header string,
+	visibility string,
+	mentionedAt time.Time,
+) (string, error) {
+	const op = "event.CollectMentionPayload"
+
+	payload, err := json.Marshal(
+		MentionPayload{
+			PostId: postId,
+			Author: Author{
+				Id:    authorId,
+				Login: authorLogin,
+			},
+			UserId:      userId,
+			Login:       login,
+			Header:      header,
+			Visibility:  visibility,
+			MentionedAt: mentionedAt,
+		},
+	)
+	if err != nil {
+		return "", e.Fail(op, err)
+	}
+
+	return string(payload), nil
+}
+
+// CollectPostEventId returns id of the event of the post. The id is unique
+// as long as the post has only one event of the type
+func CollectPostEventId(eventType string, postId int) string {
+	return fmt.Sprintf(`%s_%d`, eventType, postId)
+}
+
+// CollectAttachmentEventId returns id of the event of the attachment. The id is
+// unique as long as the attachment has only one event of the type
+func CollectAttachmentEventId(eventType string, attachmentId int) string {
+	return fmt.Sprintf(`%s_attachment_%d`, eventType, attachmentId)
+}
+
+// CollectReactionEventId returns id of the event about the reaction. User
+// can react to the post many times, so the id includes reaction time
+func CollectReactionEventId(postId int, userId int, reactedAt time.Time) string {
+	return fmt.Sprintf(`%s_%d_%d_%d`, TypeReacted, postId, userId, reactedAt.UnixNano())
+}
+
+// CollectCommentEventId returns id of the event of the comment. The id is
+// unique as long as the comment has only one event of the type
+func CollectCommentEventId(eventType string, commentId int) string {
+	return fmt.Sprintf(`%s_comment_%d`, eventType, commentId)
+}
+
+// CollectMentionEventId returns id of the event about the mention. User can be
+// mentioned in the post again after the mention was removed, so the id
+// includes mention time
+func CollectMentionEventId(postId int, userId int, mentionedAt time.Time) string {
+	return fmt.Sprintf(`%s_%d_%d_%d`, TypeMentioned, postId, userId, mentionedAt.UnixNano())
 }
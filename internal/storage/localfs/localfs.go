@@ -0,0 +1,139 @@
+package localfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Storage keeps blobs as files under the root directory.
+// Key of the blob is a slash separated path relative to the root
+type Storage struct {
+	root string
+}
+
+// New creates the root directory if it does not exist
+func New(root string) (*Storage, error) {
+	const op = "localfs.New"
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return &Storage{root: root}, nil
+}
+
+// Put writes content of the reader under the key and returns number of
+// written bytes. The blob becomes visible only when it is fully written
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	const op = "localfs.Put"
+	sendErr := func(err error) (int64, error) {
+		return 0, fail(op, err)
+	}
+
+	path, err := s.path(key)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return sendErr(err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return sendErr(err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	n, err := io.Copy(tmp, ctxReader{ctx: ctx, r: r})
+	if err != nil {
+		return sendErr(err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return sendErr(err)
+	}
+	if err = tmp.Close(); err != nil {
+		return sendErr(err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return sendErr(err)
+	}
+
+	return n, nil
+}
+
+// Get opens the blob with the key. Returns [storage.ErrNotFound]
+// if there is no such blob. Returned reader must be closed
+func (s *Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	const op = "localfs.Get"
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fail(op, storage.ErrNotFound)
+		}
+
+		return nil, fail(op, err)
+	}
+
+	return f, nil
+}
+
+// Delete deletes the blob with the key. Missing blob is not an error
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	const op = "localfs.Delete"
+
+	path, err := s.path(key)
+	if err != nil {
+		return fail(op, err)
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// path returns path of the blob file. The key must not leave the root
+func (s *Storage) path(key string) (string, error) {
+	key = filepath.FromSlash(key)
+	if !filepath.IsLocal(key) {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.root, key), nil
+}
+
+// ctxReader stops reading when the context is done
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
+
+func fail(op string, err error) error {
+	return fmt.Errorf("%s: %w", op, err)
+}
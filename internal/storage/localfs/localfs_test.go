@@ -0,0 +1,43 @@
+package localfs
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/IlianBuh/Post-service/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutGetDelete(t *testing.T) {
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	n, err := s.Put(t.Context(), "posts/1/blob", strings.NewReader("content"))
+	require.NoError(t, err)
+	require.Equal(t, int64(7), n)
+
+	r, err := s.Get(t.Context(), "posts/1/blob")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "content", string(data))
+
+	require.NoError(t, s.Delete(t.Context(), "posts/1/blob"))
+	require.NoError(t, s.Delete(t.Context(), "posts/1/blob"))
+
+	_, err = s.Get(t.Context(), "posts/1/blob")
+	require.True(t, errors.Is(err, storage.ErrNotFound))
+}
+
+func TestInvalidKey(t *testing.T) {
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"../escape", "/abs", ""} {
+		_, err = s.Put(t.Context(), key, strings.NewReader("content"))
+		require.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
@@ -0,0 +1,387 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/storage"
+	"github.com/IlianBuh/Post-service/internal/storage/events"
+)
+
+const slctAttachmentQuery = `
+	SELECT attachment_id, post_id, file_name, size, mime_type, sha256, blob_key, created_at,
+		COALESCE(image_status, ''), COALESCE(width, 0), COALESCE(height, 0)
+	FROM attachments`
+
+// SaveAttachment saves metadata of the file attached to the post and returns
+// it with the id and creation time. Returns [storage.ErrNotFound] if there is
+// no such post, [storage.ErrNotCreator] if the user is not creator of the post
+// and [storage.ErrFileExists] if the post already has a file with the name
+func (s *Storage) SaveAttachment(
+	ctx context.Context,
+	userId int,
+	att models.Attachment,
+) (models.Attachment, error) {
+	const (
+		op         = "postgres.SaveAttachment"
+		insrtQuery = `
+			INSERT INTO attachments(post_id, file_name, size, mime_type, sha256, blob_key, image_status)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+			ON CONFLICT (post_id, file_name) DO NOTHING
+			RETURNING attachment_id, created_at;`
+	)
+	sendErr := func(err error) (models.Attachment, error) {
+		return models.Attachment{}, fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	rec, err := s.post(ctx, tx, att.PostId)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if !s.isCreator(rec.userId, userId) {
+		return sendErr(storage.ErrNotCreator)
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		insrtQuery,
+		att.PostId,
+		att.FileName,
+		att.Size,
+		att.MimeType,
+		att.Sha256,
+		att.BlobKey,
+		att.ImageStatus,
+	).Scan(&att.Id, &att.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sendErr(storage.ErrFileExists)
+		}
+
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return att, nil
+}
+
+// Attachment returns metadata of the file attached to the post with variants
+// of the image. Returns [storage.ErrNotFound] if the post has no file with the name
+func (s *Storage) Attachment(
+	ctx context.Context,
+	postId int,
+	fileName string,
+) (models.Attachment, error) {
+	const (
+		op        = "postgres.Attachment"
+		slctQuery = slctAttachmentQuery + `
+			WHERE post_id = $1 AND file_name = $2;`
+	)
+	sendErr := func(err error) (models.Attachment, error) {
+		return models.Attachment{}, fail(op, err)
+	}
+
+	att, err := scanAttachment(s.db.QueryRowContext(ctx, slctQuery, postId, fileName))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sendErr(storage.ErrNotFound)
+		}
+
+		return sendErr(err)
+	}
+
+	if att.ImageStatus == models.ImageReady {
+		att.Variants, err = s.imageVariants(ctx, att.Id)
+		if err != nil {
+			return sendErr(err)
+		}
+	}
+
+	return att, nil
+}
+
+// PendingImages returns at most limit image attachments of existing
+// posts which are waiting for their variants. Images with less failed
+// attempts go first, so failing images do not block the new ones
+func (s *Storage) PendingImages(
+	ctx context.Context,
+	limit int,
+) ([]models.Attachment, error) {
+	const (
+		op        = "postgres.PendingImages"
+		slctQuery = slctAttachmentQuery + `
+			WHERE image_status = 'pending' AND post_id IS NOT NULL
+			ORDER BY image_attempts, attachment_id
+			LIMIT $1;`
+	)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	atts := make([]models.Attachment, 0, limit)
+	for rows.Next() {
+		att, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fail(op, err)
+		}
+
+		atts = append(atts, att)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return atts, nil
+}
+
+// SaveImageVariants marks the pending image as ready, replaces its' content
+// with the stripped one and saves the variants. Event about the ready image is
+// saved in the same transaction. Returns [storage.ErrNotFound] if the image is
+// not pending anymore or its' post has been purged
+func (s *Storage) SaveImageVariants(
+	ctx context.Context,
+	att models.Attachment,
+) error {
+	const (
+		op        = "postgres.SaveImageVariants"
+		updtQuery = `
+			UPDATE attachments
+			SET image_status='ready', width=$2, height=$3, size=$4, sha256=$5, blob_key=$6
+			WHERE attachment_id = $1 AND image_status = 'pending' AND post_id IS NOT NULL
+			RETURNING post_id, file_name;`
+		insrtQuery = `
+			INSERT INTO attachment_variants(attachment_id, name, width, height, size, mime_type, blob_key)
+			VALUES ($1, $2, $3, $4, $5, $6, $7);`
+	)
+	sendErr := func(err error) error {
+		return fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		updtQuery,
+		att.Id,
+		att.Width,
+		att.Height,
+		att.Size,
+		att.Sha256,
+		att.BlobKey,
+	).Scan(&att.PostId, &att.FileName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sendErr(storage.ErrNotFound)
+		}
+
+		return sendErr(err)
+	}
+
+	insrtStmt, err := tx.PrepareContext(ctx, insrtQuery)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer insrtStmt.Close()
+
+	names := make([]string, 0, len(att.Variants))
+	for _, v := range att.Variants {
+		_, err = insrtStmt.ExecContext(ctx, att.Id, v.Name, v.Width, v.Height, v.Size, v.MimeType, v.BlobKey)
+		if err != nil {
+			return sendErr(err)
+		}
+
+		names = append(names, v.Name)
+	}
+
+	payload, err := events.CollectThumbnailsPayload(att.PostId, att.FileName, att.Width, att.Height, names)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	eventId := events.CollectAttachmentEventId(events.TypeThumbnailsReady, att.Id)
+	if err = s.saveEvent(ctx, tx, eventId, events.TypeThumbnailsReady, payload); err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// FailImage marks the pending image as the one which can't be processed
+func (s *Storage) FailImage(
+	ctx context.Context,
+	attachmentId int,
+) error {
+	const (
+		op        = "postgres.FailImage"
+		updtQuery = `
+			UPDATE attachments SET image_status='failed'
+			WHERE attachment_id = $1 AND image_status = 'pending';`
+	)
+
+	if _, err := s.db.ExecContext(ctx, updtQuery, attachmentId); err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// RetryImage counts failed attempt to process the pending image. The image
+// is marked as failed when it reaches maxAttempts. Reports whether the image
+// is marked as failed
+func (s *Storage) RetryImage(
+	ctx context.Context,
+	attachmentId int,
+	maxAttempts int,
+) (bool, error) {
+	const (
+		op        = "postgres.RetryImage"
+		updtQuery = `
+			UPDATE attachments SET image_attempts = image_attempts + 1,
+				image_status = CASE WHEN image_attempts + 1 >= $2 THEN 'failed' ELSE image_status END
+			WHERE attachment_id = $1 AND image_status = 'pending'
+			RETURNING image_status;`
+	)
+
+	var status string
+	err := s.db.QueryRowContext(ctx, updtQuery, attachmentId, maxAttempts).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fail(op, err)
+	}
+
+	return status == string(models.ImageFailed), nil
+}
+
+// PurgeAttachments deletes metadata of at most limit files which posts have
+// been purged and returns blob keys of the deleted files and their variants
+func (s *Storage) PurgeAttachments(
+	ctx context.Context,
+	limit int,
+) ([]string, error) {
+	const (
+		op       = "postgres.PurgeAttachments"
+		dltQuery = `
+			WITH deleted AS (
+				DELETE FROM attachments
+				WHERE attachment_id IN (
+					SELECT attachment_id
+					FROM attachments
+					WHERE post_id IS NULL
+					LIMIT $1
+					FOR UPDATE SKIP LOCKED
+				)
+				RETURNING attachment_id, blob_key
+			)
+			SELECT blob_key FROM deleted
+			UNION ALL
+			SELECT v.blob_key
+			FROM attachment_variants AS v
+				JOIN deleted AS d ON d.attachment_id = v.attachment_id;`
+	)
+
+	rows, err := s.db.QueryContext(ctx, dltQuery, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0, limit)
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, fail(op, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return keys, nil
+}
+
+// imageVariants returns variants of the image ordered by their width
+func (s *Storage) imageVariants(
+	ctx context.Context,
+	attachmentId int,
+) ([]models.ImageVariant, error) {
+	const (
+		op        = "postgres.imageVariants"
+		slctQuery = `
+			SELECT name, width, height, size, mime_type, blob_key
+			FROM attachment_variants
+			WHERE attachment_id = $1
+			ORDER BY width, name;`
+	)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, attachmentId)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	var variants []models.ImageVariant
+	for rows.Next() {
+		var v models.ImageVariant
+		if err = rows.Scan(&v.Name, &v.Width, &v.Height, &v.Size, &v.MimeType, &v.BlobKey); err != nil {
+			return nil, fail(op, err)
+		}
+
+		variants = append(variants, v)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return variants, nil
+}
+
+// scanAttachment scans the row selected by slctAttachmentQuery
+func scanAttachment(row scanner) (models.Attachment, error) {
+	var att models.Attachment
+	err := row.Scan(
+		&att.Id,
+		&att.PostId,
+		&att.FileName,
+		&att.Size,
+		&att.MimeType,
+		&att.Sha256,
+		&att.BlobKey,
+		&att.CreatedAt,
+		&att.ImageStatus,
+		&att.Width,
+		&att.Height,
+	)
+
+	return att, err
+}
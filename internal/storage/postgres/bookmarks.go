@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	"github.com/IlianBuh/Post-service/internal/storage"
+	"github.com/lib/pq"
+)
+
+// AddBookmark saves the post into the reading list of the user. Bookmarking
+// the post twice changes nothing. Returns [storage.ErrNotFound] if there is no
+// such alive post
+func (s *Storage) AddBookmark(
+	ctx context.Context,
+	userId int,
+	list string,
+	postId int,
+) error {
+	const (
+		op        = "postgres.AddBookmark"
+		slctQuery = `
+			SELECT post_id
+			FROM posts
+			WHERE post_id = $1 AND deleted_at IS NULL
+			FOR KEY SHARE;`
+		insrtQuery = `
+			INSERT INTO bookmarks(user_id, list_name, post_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, list_name, post_id) DO NOTHING;`
+	)
+	sendErr := func(err error) error {
+		return fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	if err = tx.QueryRowContext(ctx, slctQuery, postId).Scan(&postId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sendErr(storage.ErrNotFound)
+		}
+
+		return sendErr(err)
+	}
+
+	if _, err = tx.ExecContext(ctx, insrtQuery, userId, list, postId); err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// RemoveBookmark removes the post from the reading list of the
+// user. Missing bookmark is not an error
+func (s *Storage) RemoveBookmark(
+	ctx context.Context,
+	userId int,
+	list string,
+	postId int,
+) error {
+	const (
+		op       = "postgres.RemoveBookmark"
+		dltQuery = `
+			DELETE FROM bookmarks
+			WHERE user_id = $1 AND list_name = $2 AND post_id = $3;`
+	)
+
+	if _, err := s.db.ExecContext(ctx, dltQuery, userId, list, postId); err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// Bookmarks returns at most limit bookmarks of the reading list of the viewer
+// ordered from the most recently added. Bookmarks of posts the viewer can't
+// read anymore are skipped. Only bookmarks placed after the cursor are
+// returned, the cursor is keyed on the bookmarking time. Bookmarks and their
+// posts are read from one snapshot, so every found bookmark has its' post
+func (s *Storage) Bookmarks(
+	ctx context.Context,
+	viewer models.Viewer,
+	list string,
+	after cursor.Cursor,
+	limit int,
+) ([]models.Bookmark, error) {
+	const (
+		op        = "postgres.Bookmarks"
+		slctQuery = `
+			SELECT b.post_id, b.created_at
+			FROM bookmarks b
+			JOIN posts p ON p.post_id = b.post_id
+			WHERE b.user_id = $1 AND b.list_name = $2
+				AND p.deleted_at IS NULL
+				AND (p.status = 'published' OR p.user_id = $1)
+				AND post_visible(p.visibility, p.user_id, $1, $3, FALSE)
+				AND ($4::TIMESTAMPTZ IS NULL OR (b.created_at, b.post_id) < ($4, $5))
+			ORDER BY b.created_at DESC, b.post_id DESC
+			LIMIT $6;`
+	)
+	sendErr := func(err error) ([]models.Bookmark, error) {
+		return nil, fail(op, err)
+	}
+
+	afterTime, afterId := keysetArgs(after)
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(
+		ctx,
+		slctQuery,
+		viewer.Id,
+		list,
+		pq.Array(toInt64s(viewer.Following)),
+		afterTime,
+		afterId,
+		limit,
+	)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer rows.Close()
+
+	bookmarks := make([]models.Bookmark, 0, limit)
+	postIds := make([]int, 0, limit)
+	for rows.Next() {
+		b := models.Bookmark{List: list}
+		if err = rows.Scan(&b.Post.Id, &b.CreatedAt); err != nil {
+			return sendErr(err)
+		}
+
+		bookmarks = append(bookmarks, b)
+		postIds = append(postIds, b.Post.Id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return sendErr(err)
+	}
+
+	if len(postIds) == 0 {
+		return bookmarks, nil
+	}
+
+	posts, err := s.posts(ctx, tx, viewer, postIds)
+	if err != nil {
+		return sendErr(err)
+	}
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	byId := make(map[int]models.Post, len(posts))
+	for _, post := range posts {
+		byId[post.Id] = post
+	}
+	for i := range bookmarks {
+		bookmarks[i].Post = byId[bookmarks[i].Post.Id]
+	}
+
+	return bookmarks, nil
+}
+
+// BookmarkLists returns reading lists of the user with number
+// of bookmarks in every list ordered by list name
+func (s *Storage) BookmarkLists(
+	ctx context.Context,
+	userId int,
+) ([]models.BookmarkList, error) {
+	const (
+		op        = "postgres.BookmarkLists"
+		slctQuery = `
+			SELECT list_name, COUNT(*)
+			FROM bookmarks
+			WHERE user_id = $1
+			GROUP BY list_name
+			ORDER BY list_name;`
+	)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, userId)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	var lists []models.BookmarkList
+	for rows.Next() {
+		var l models.BookmarkList
+		if err = rows.Scan(&l.Name, &l.Count); err != nil {
+			return nil, fail(op, err)
+		}
+
+		lists = append(lists, l)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return lists, nil
+}
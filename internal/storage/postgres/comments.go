@@ -0,0 +1,377 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	"github.com/IlianBuh/Post-service/internal/storage"
+	"github.com/IlianBuh/Post-service/internal/storage/events"
+	"github.com/lib/pq"
+)
+
+const slctCommentQuery = `
+	SELECT comment_id, post_id, COALESCE(parent_id, 0), user_id, content,
+		replies_count, created_at, updated_at, deleted_at
+	FROM comments`
+
+// SaveComment saves the comment to the published post and returns it with
+// id and creation time. Comment counter of the post, reply counter of the
+// parent and event about the new comment are updated in the same transaction.
+// Returns [storage.ErrNotFound] if there is no such published post and
+// [storage.ErrNoParent] if the post has no alive parent comment
+func (s *Storage) SaveComment(
+	ctx context.Context,
+	comment models.Comment,
+) (models.Comment, error) {
+	const (
+		op        = "postgres.SaveComment"
+		postQuery = `
+			UPDATE posts SET comments_count = comments_count + 1
+			WHERE post_id = $1 AND deleted_at IS NULL AND status = 'published'
+			RETURNING user_id;`
+		prntQuery = `
+			UPDATE comments SET replies_count = replies_count + 1
+			WHERE comment_id = $1 AND post_id = $2 AND deleted_at IS NULL
+			RETURNING user_id;`
+		insrtQuery = `
+			INSERT INTO comments(post_id, parent_id, user_id, content)
+			VALUES ($1, NULLIF($2, 0), $3, $4)
+			RETURNING comment_id, created_at;`
+	)
+	sendErr := func(err error) (models.Comment, error) {
+		return models.Comment{}, fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	var postAuthorId int
+	if err = tx.QueryRowContext(ctx, postQuery, comment.PostId).Scan(&postAuthorId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sendErr(storage.ErrNotFound)
+		}
+
+		return sendErr(err)
+	}
+
+	var parentAuthorId int
+	if comment.ParentId != 0 {
+		err = tx.QueryRowContext(ctx, prntQuery, comment.ParentId, comment.PostId).Scan(&parentAuthorId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return sendErr(storage.ErrNoParent)
+			}
+
+			return sendErr(err)
+		}
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		insrtQuery,
+		comment.PostId,
+		comment.ParentId,
+		comment.UserId,
+		comment.Content,
+	).Scan(&comment.Id, &comment.CreatedAt)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	payload, err := events.CollectCommentPayload(
+		comment.Id,
+		comment.PostId,
+		postAuthorId,
+		comment.ParentId,
+		parentAuthorId,
+		comment.UserId,
+		comment.CreatedAt,
+	)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	eventId := events.CollectCommentEventId(events.TypeCommentCreated, comment.Id)
+	if err = s.saveEvent(ctx, tx, eventId, events.TypeCommentCreated, payload); err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return comment, nil
+}
+
+// EditComment replaces content of the comment if the user is its creator and
+// returns the edited comment. Returns [storage.ErrNotFound] if there is no
+// such alive comment and [storage.ErrNotCreator] if the user is not creator
+func (s *Storage) EditComment(
+	ctx context.Context,
+	commentId int,
+	userId int,
+	content string,
+) (models.Comment, error) {
+	const (
+		op        = "postgres.EditComment"
+		updtQuery = `
+			UPDATE comments SET content=$2, updated_at=NOW()
+			WHERE comment_id = $1
+			RETURNING comment_id, post_id, COALESCE(parent_id, 0), user_id, content,
+				replies_count, created_at, updated_at, deleted_at;`
+	)
+	sendErr := func(err error) (models.Comment, error) {
+		return models.Comment{}, fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	if err = s.lockComment(ctx, tx, commentId, userId); err != nil {
+		return sendErr(err)
+	}
+
+	comment, err := scanComment(tx.QueryRowContext(ctx, updtQuery, commentId, content))
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return comment, nil
+}
+
+// DeleteComment deletes the comment if the user is its creator. Deleted
+// comment loses its' content but is kept while it holds shown replies.
+// Comment counter of the post and replies counters of the ancestors which
+// stop showing the comment are updated in the same transaction. Returns
+// [storage.ErrNotFound] if there is no such alive comment and
+// [storage.ErrNotCreator] if the user is not creator
+func (s *Storage) DeleteComment(
+	ctx context.Context,
+	commentId int,
+	userId int,
+) error {
+	const (
+		op       = "postgres.DeleteComment"
+		dltQuery = `
+			UPDATE comments SET content='', deleted_at=NOW()
+			WHERE comment_id = $1
+			RETURNING post_id, parent_id, replies_count > 0;`
+		postQuery = `
+			UPDATE posts SET comments_count = comments_count - 1 WHERE post_id = $1;`
+		parentQuery = `
+			UPDATE comments SET replies_count = replies_count - 1
+			WHERE comment_id = $1
+			RETURNING parent_id, deleted_at IS NULL OR replies_count > 0;`
+	)
+	sendErr := func(err error) error {
+		return fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	if err = s.lockComment(ctx, tx, commentId, userId); err != nil {
+		return sendErr(err)
+	}
+
+	var (
+		postId   int
+		parentId sql.NullInt64
+		shown    bool
+	)
+	err = tx.QueryRowContext(ctx, dltQuery, commentId).Scan(&postId, &parentId, &shown)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if _, err = tx.ExecContext(ctx, postQuery, postId); err != nil {
+		return sendErr(err)
+	}
+
+	// deleted comment with shown replies is still shown, otherwise the parent
+	// loses the reply and can become hidden too if it is deleted already
+	for parentId.Valid && !shown {
+		err = tx.QueryRowContext(ctx, parentQuery, parentId.Int64).Scan(&parentId, &shown)
+		if err != nil {
+			return sendErr(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// Comments returns at most limit direct replies to the parent comment of the
+// post, top-level comments if parentId is zero, ordered from the oldest.
+// Deleted comments are returned only if they have replies. Only comments
+// placed after the cursor are returned, the cursor keeps id of the comment in
+// place of post id
+func (s *Storage) Comments(
+	ctx context.Context,
+	postId int,
+	parentId int,
+	after cursor.Cursor,
+	limit int,
+) ([]models.Comment, error) {
+	const (
+		op        = "postgres.Comments"
+		slctQuery = slctCommentQuery + `
+			WHERE post_id = $1
+				AND parent_id IS NOT DISTINCT FROM NULLIF($2, 0)
+				AND (deleted_at IS NULL OR replies_count > 0)
+				AND ($3::TIMESTAMPTZ IS NULL OR (created_at, comment_id) > ($3, $4))
+			ORDER BY created_at, comment_id
+			LIMIT $5;`
+	)
+
+	afterTime, afterId := keysetArgs(after)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, postId, parentId, afterTime, afterId, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	comments, err := scanComments(rows, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return comments, nil
+}
+
+// Replies returns at most limit oldest direct replies to every comment of the
+// parentIds. Deleted replies are returned only if they have replies
+func (s *Storage) Replies(
+	ctx context.Context,
+	parentIds []int,
+	limit int,
+) ([]models.Comment, error) {
+	const (
+		op        = "postgres.Replies"
+		slctQuery = `
+			SELECT comment_id, post_id, parent_id, user_id, content,
+				replies_count, created_at, updated_at, deleted_at
+			FROM (
+				SELECT c.*, ROW_NUMBER() OVER (
+					PARTITION BY c.parent_id ORDER BY c.created_at, c.comment_id
+				) AS rn
+				FROM comments c
+				WHERE c.parent_id = ANY($1) AND (c.deleted_at IS NULL OR c.replies_count > 0)
+			) r
+			WHERE rn <= $2
+			ORDER BY parent_id, created_at, comment_id;`
+	)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, pq.Array(toInt64s(parentIds)), limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	replies, err := scanComments(rows, len(parentIds)*limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return replies, nil
+}
+
+// lockComment locks the alive comment and checks if the user is its
+// creator. Returns [storage.ErrNotFound] or [storage.ErrNotCreator]
+func (s *Storage) lockComment(
+	ctx context.Context,
+	tx *sql.Tx,
+	commentId int,
+	userId int,
+) error {
+	const (
+		op        = "postgres.lockComment"
+		slctQuery = `
+			SELECT user_id
+			FROM comments
+			WHERE comment_id = $1 AND deleted_at IS NULL
+			FOR UPDATE;`
+	)
+
+	var recUserId int
+	if err := tx.QueryRowContext(ctx, slctQuery, commentId).Scan(&recUserId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fail(op, storage.ErrNotFound)
+		}
+
+		return fail(op, err)
+	}
+
+	if !s.isCreator(recUserId, userId) {
+		return fail(op, storage.ErrNotCreator)
+	}
+
+	return nil
+}
+
+// scanComments scans all rows selected by slctCommentQuery
+func scanComments(rows *sql.Rows, capacity int) ([]models.Comment, error) {
+	defer rows.Close()
+
+	comments := make([]models.Comment, 0, capacity)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// scanComment scans one row selected by slctCommentQuery into the comment model
+func scanComment(row scanner) (models.Comment, error) {
+	var (
+		comment   models.Comment
+		updatedAt sql.NullTime
+		deletedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&comment.Id,
+		&comment.PostId,
+		&comment.ParentId,
+		&comment.UserId,
+		&comment.Content,
+		&comment.RepliesCount,
+		&comment.CreatedAt,
+		&updatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	comment.UpdatedAt = updatedAt.Time
+	comment.DeletedAt = deletedAt.Time
+
+	return comment, nil
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+// reserveIdempotencyKey reserves the key of the user within the transaction.
+// If the key was already used with the same fingerprint, id of the post saved
+// with it is returned, zero id means that the key is reserved now. Expired key
+// is reserved again, so the result does not depend on the purger. Returns
+// [storage.ErrKeyReused] if the key was used with another fingerprint
+func (s *Storage) reserveIdempotencyKey(
+	ctx context.Context,
+	tx *sql.Tx,
+	userId int,
+	key models.IdempotencyKey,
+) (int, error) {
+	const (
+		op         = "postgres.reserveIdempotencyKey"
+		insrtQuery = `
+			INSERT INTO idempotency_keys(user_id, idempotency_key, fingerprint)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, idempotency_key) DO UPDATE
+			SET fingerprint = EXCLUDED.fingerprint, post_id = NULL, created_at = NOW()
+			WHERE $4::BIGINT > 0
+				AND idempotency_keys.created_at <= NOW() - $4 * INTERVAL '1 microsecond';`
+		slctQuery = `
+			SELECT fingerprint, post_id
+			FROM idempotency_keys
+			WHERE user_id = $1 AND idempotency_key = $2;`
+	)
+	sendErr := func(err error) (int, error) {
+		return 0, fail(op, err)
+	}
+
+	// concurrent request with the same key waits here
+	// until the transaction which reserved the key ends
+	res, err := tx.ExecContext(
+		ctx,
+		insrtQuery,
+		userId,
+		key.Key,
+		key.Fingerprint,
+		key.TTL.Microseconds(),
+	)
+	if err != nil {
+		return sendErr(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return sendErr(err)
+	}
+	if n == 1 {
+		return 0, nil
+	}
+
+	var (
+		fingerprint string
+		postId      sql.NullInt64
+	)
+	err = tx.QueryRowContext(ctx, slctQuery, userId, key.Key).Scan(&fingerprint, &postId)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if fingerprint != key.Fingerprint {
+		return sendErr(storage.ErrKeyReused)
+	}
+	if !postId.Valid {
+		return sendErr(errors.New("idempotency key is not bound to post"))
+	}
+
+	return int(postId.Int64), nil
+}
+
+// bindIdempotencyKey binds the reserved key of the user to the saved post
+func (s *Storage) bindIdempotencyKey(
+	ctx context.Context,
+	tx *sql.Tx,
+	userId int,
+	key models.IdempotencyKey,
+	postId int,
+) error {
+	const (
+		op        = "postgres.bindIdempotencyKey"
+		updtQuery = `
+			UPDATE idempotency_keys SET post_id=$3
+			WHERE user_id = $1 AND idempotency_key = $2;`
+	)
+
+	if _, err := tx.ExecContext(ctx, updtQuery, userId, key.Key, postId); err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes at most limit idempotency keys created before
+// the time. Returns number of deleted keys
+func (s *Storage) PurgeIdempotencyKeys(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int, error) {
+	const (
+		op       = "postgres.PurgeIdempotencyKeys"
+		dltQuery = `
+			DELETE FROM idempotency_keys
+			WHERE (user_id, idempotency_key) IN (
+				SELECT user_id, idempotency_key
+				FROM idempotency_keys
+				WHERE created_at < $1
+				ORDER BY created_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			);`
+	)
+
+	res, err := s.db.ExecContext(ctx, dltQuery, before, limit)
+	if err != nil {
+		return 0, fail(op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fail(op, err)
+	}
+
+	return int(n), nil
+}
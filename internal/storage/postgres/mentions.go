@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/storage/events"
+	"github.com/lib/pq"
+)
+
+// replaceMentions replaces mentions of the post with the list. Users who are
+// newly mentioned in the published post are notified by events, mentions of
+// unpublished posts are notified when the post is published
+func (s *Storage) replaceMentions(
+	ctx context.Context,
+	tx *sql.Tx,
+	postId int,
+	mentions []models.Mention,
+) error {
+	const (
+		op       = "postgres.replaceMentions"
+		dltQuery = `
+			DELETE FROM post_mentions
+			WHERE post_id = $1 AND NOT (user_id = ANY($2));`
+		insrtQuery = `
+			INSERT INTO post_mentions(post_id, user_id, login)
+			SELECT $1, m.user_id, m.login
+			FROM UNNEST($2::INT[], $3::TEXT[]) AS m(user_id, login)
+			ON CONFLICT (post_id, user_id) DO NOTHING
+			RETURNING user_id, login, created_at;`
+	)
+	sendErr := func(err error) error {
+		return fail(op, err)
+	}
+
+	userIds := make([]int, 0, len(mentions))
+	logins := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		userIds = append(userIds, m.UserId)
+		logins = append(logins, m.Login)
+	}
+
+	_, err := tx.ExecContext(ctx, dltQuery, postId, pq.Array(toInt64s(userIds)))
+	if err != nil {
+		return sendErr(err)
+	}
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		insrtQuery,
+		postId,
+		pq.Array(toInt64s(userIds)),
+		pq.Array(logins),
+	)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer rows.Close()
+
+	var (
+		added       []models.Mention
+		mentionedAt time.Time
+	)
+	for rows.Next() {
+		var m models.Mention
+		if err = rows.Scan(&m.UserId, &m.Login, &mentionedAt); err != nil {
+			return sendErr(err)
+		}
+
+		added = append(added, m)
+	}
+	if err = rows.Err(); err != nil {
+		return sendErr(err)
+	}
+
+	if err = s.notifyMentioned(ctx, tx, postId, added, mentionedAt); err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// notifyMentioned saves events about the users mentioned in the post.
+// Nothing is saved while the post is not published
+func (s *Storage) notifyMentioned(
+	ctx context.Context,
+	tx *sql.Tx,
+	postId int,
+	mentions []models.Mention,
+	mentionedAt time.Time,
+) error {
+	const (
+		op        = "postgres.notifyMentioned"
+		slctQuery = `
+			SELECT user_id, login, header, status, visibility
+			FROM posts
+			WHERE post_id = $1;`
+	)
+	sendErr := func(err error) error {
+		return fail(op, err)
+	}
+
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	var (
+		authorId    int
+		authorLogin string
+		header      string
+		status      models.PostStatus
+		visibility  string
+	)
+	err := tx.QueryRowContext(ctx, slctQuery, postId).Scan(
+		&authorId,
+		&authorLogin,
+		&header,
+		&status,
+		&visibility,
+	)
+	if err != nil {
+		return sendErr(err)
+	}
+	if status != models.StatusPublished {
+		return nil
+	}
+
+	for _, m := range mentions {
+		payload, err := events.CollectMentionPayload(
+			postId,
+			authorId,
+			authorLogin,
+			m.UserId,
+			m.Login,
+			header,
+			visibility,
+			mentionedAt,
+		)
+		if err != nil {
+			return sendErr(err)
+		}
+
+		eventId := events.CollectMentionEventId(postId, m.UserId, mentionedAt)
+		if err = s.saveEvent(ctx, tx, eventId, events.TypeMentioned, payload); err != nil {
+			return sendErr(err)
+		}
+	}
+
+	return nil
+}
+
+// mentions returns users mentioned in the post
+func (s *Storage) mentions(
+	ctx context.Context,
+	tx *sql.Tx,
+	postId int,
+) ([]models.Mention, error) {
+	const (
+		op        = "postgres.mentions"
+		slctQuery = `
+			SELECT user_id, login
+			FROM post_mentions
+			WHERE post_id = $1
+			ORDER BY user_id;`
+	)
+
+	rows, err := tx.QueryContext(ctx, slctQuery, postId)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	var res []models.Mention
+	for rows.Next() {
+		var m models.Mention
+		if err = rows.Scan(&m.UserId, &m.Login); err != nil {
+			return nil, fail(op, err)
+		}
+
+		res = append(res, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return res, nil
+}
@@ -17,10 +17,13 @@ type Storage struct {
 	db *sql.DB
 }
 type record struct {
-	postId  int
-	userId  int
-	header  string
-	content string
+	postId   int
+	userId   int
+	header   string
+	content  string
+	format   models.ContentFormat
+	rendered models.RenderedContent
+	version  int
 }
 
 func New(
@@ -54,13 +57,26 @@ func New(
 	return &Storage{db: db}, nil
 }
 
+// Save saves new post with the status and visibility and users mentioned in
+// it. Events about the new post and the mentions are saved only if the post is
+// published. publishAt is used only by scheduled posts. If the idempotency key
+// was already used by the user, nothing is saved and id of the post saved with
+// the key is returned. [storage.ErrKeyReused] is returned if the key was used
+// with another payload. Zero key disables the check
 func (s *Storage) Save(
 	ctx context.Context,
 	userId int,
 	login string,
 	header string,
 	content string,
+	format models.ContentFormat,
+	rendered models.RenderedContent,
 	themes []string,
+	status models.PostStatus,
+	publishAt time.Time,
+	visibility models.Visibility,
+	mentions []models.Mention,
+	idemKey models.IdempotencyKey,
 ) (int, error) {
 	const op = "postgres.Save"
 	var (
@@ -83,20 +99,66 @@ func (s *Storage) Save(
 	}
 	defer tx.Rollback()
 
-	postId, err = s.save(ctx, tx, userId, &login, &header, &content, themes)
+	if !idemKey.IsZero() {
+		postId, err = s.reserveIdempotencyKey(ctx, tx, userId, idemKey)
+		if err != nil {
+			return sendErr(err)
+		}
+		if postId != 0 {
+			return postId, nil
+		}
+	}
+
+	postId, err = s.save(
+		ctx,
+		tx,
+		userId,
+		&login,
+		&header,
+		&content,
+		format,
+		&rendered,
+		themes,
+		status,
+		publishAt,
+		visibility,
+	)
 	if err != nil {
 		return sendErr(err)
 	}
 
-	payload, err := events.CollectEventPayload(userId, login, header, time.Now())
-	if err != nil {
-		return 0, fail(op, err)
+	if !idemKey.IsZero() {
+		err = s.bindIdempotencyKey(ctx, tx, userId, idemKey, postId)
+		if err != nil {
+			return sendErr(err)
+		}
 	}
 
-	eventId := events.CollectEventId(userId)
-	err = s.saveEvent(ctx, tx, eventId, events.TypeCteated, payload)
-	if err != nil {
-		return sendErr(err)
+	if len(mentions) != 0 {
+		if err = s.replaceMentions(ctx, tx, postId, mentions); err != nil {
+			return sendErr(err)
+		}
+	}
+
+	if status == models.StatusPublished {
+		payload, err := events.CollectEventPayload(
+			postId,
+			userId,
+			login,
+			header,
+			rendered.Excerpt,
+			time.Now(),
+			string(visibility),
+		)
+		if err != nil {
+			return 0, fail(op, err)
+		}
+
+		eventId := events.CollectPostEventId(events.TypeCteated, postId)
+		err = s.saveEvent(ctx, tx, eventId, events.TypeCteated, payload)
+		if err != nil {
+			return sendErr(err)
+		}
 	}
 
 	err = tx.Commit()
@@ -115,7 +177,12 @@ func (s *Storage) save(
 	login *string,
 	header *string,
 	content *string,
+	format models.ContentFormat,
+	rendered *models.RenderedContent,
 	themes []string,
+	status models.PostStatus,
+	publishAt time.Time,
+	visibility models.Visibility,
 ) (int, error) {
 	const (
 		op = "storage.saveThemes"
@@ -126,7 +193,20 @@ func (s *Storage) save(
 	ctx, cncl := context.WithCancel(ctx)
 	defer cncl()
 
-	postId, thmIds, err := s.fetchAllIds(ctx, tx, userId, login, header, content, themes)
+	postId, thmIds, err := s.fetchAllIds(
+		ctx,
+		tx,
+		userId,
+		login,
+		header,
+		content,
+		format,
+		rendered,
+		themes,
+		status,
+		publishAt,
+		visibility,
+	)
 	if err != nil {
 		return sendErr(err)
 	}
@@ -147,12 +227,20 @@ func (s *Storage) savePost(
 	login *string,
 	header *string,
 	content *string,
+	format models.ContentFormat,
+	rendered *models.RenderedContent,
+	status models.PostStatus,
+	publishAt time.Time,
+	visibility models.Visibility,
 ) (postId int, err error) {
 	const (
 		op            = "postgres.savePost"
 		insertNewPost = `
-			INSERT INTO posts(user_id, login, header, content)
-			VALUES($1, $2, $3, $4)
+			INSERT INTO posts(
+				user_id, login, header, content, status, publish_at, visibility,
+				content_format, content_html, excerpt, word_count, reading_seconds
+			)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 			RETURNING post_id`
 	)
 	sendErr := func(err error) (int, error) {
@@ -165,7 +253,26 @@ func (s *Storage) savePost(
 	}
 	defer insrtStmt.Close()
 
-	row := insrtStmt.QueryRowContext(ctx, userId, *login, *header, *content)
+	var publishTime sql.NullTime
+	if !publishAt.IsZero() {
+		publishTime = sql.NullTime{Time: publishAt, Valid: true}
+	}
+
+	row := insrtStmt.QueryRowContext(
+		ctx,
+		userId,
+		*login,
+		*header,
+		*content,
+		status,
+		publishTime,
+		visibility,
+		format,
+		rendered.HTML,
+		rendered.Excerpt,
+		rendered.WordCount,
+		int(rendered.ReadingTime.Seconds()),
+	)
 	if err = row.Scan(&postId); err != nil {
 		return sendErr(err)
 	}
@@ -232,13 +339,26 @@ func (s *Storage) saveEvent(
 	return nil
 }
 
+// Update updates the post if the user is its creator and the post has the
+// expected version. Zero version skips the check. Only fields named in the mask
+// are replaced, then addThemes are added to the post and removeThemes are
+// removed from it. Mentions are replaced if the header or the content is
+// replaced. The post is read and written in one transaction,
+// [storage.ErrConflict] is returned on version mismatch
 func (s *Storage) Update(
 	ctx context.Context,
 	postId int,
 	userId int,
 	header string,
 	content string,
+	format models.ContentFormat,
+	rendered models.RenderedContent,
 	themes []string,
+	mask models.UpdateMask,
+	addThemes []string,
+	removeThemes []string,
+	mentions []models.Mention,
+	version int,
 ) (int, error) {
 	const op = "postgres.Update"
 	var (
@@ -255,12 +375,40 @@ func (s *Storage) Update(
 	ctx, cncl := context.WithCancel(ctx)
 	defer cncl()
 
-	rec, err = s.makePostRec(ctx, postId, userId, header, content)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return sendErr(err)
 	}
+	defer tx.Rollback()
 
-	err = s.update(ctx, rec, themes)
+	rec, err = s.makePostRec(
+		ctx,
+		tx,
+		postId,
+		userId,
+		header,
+		content,
+		format,
+		rendered,
+		mask,
+		version,
+	)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	err = s.update(ctx, tx, rec, themes, mask.Themes, addThemes, removeThemes)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if mask.Header || mask.Content {
+		if err = s.replaceMentions(ctx, tx, postId, mentions); err != nil {
+			return sendErr(err)
+		}
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return sendErr(err)
 	}
@@ -268,11 +416,16 @@ func (s *Storage) Update(
 	return postId, nil
 }
 
-// update updates all information related to the post that has the postId
+// update updates all information related to the post that has the postId.
+// Themes of the post are replaced only if replaceThemes is set
 func (s *Storage) update(
 	ctx context.Context,
+	tx *sql.Tx,
 	rec record,
 	themes []string,
+	replaceThemes bool,
+	addThemes []string,
+	removeThemes []string,
 ) error {
 	const (
 		op = "postgres.update"
@@ -281,37 +434,54 @@ func (s *Storage) update(
 		return fail(op, err)
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	err := s.saveRevision(ctx, tx, rec.postId)
 	if err != nil {
 		return sendErr(err)
 	}
-	defer tx.Rollback()
 
 	err = s.updatePost(ctx, tx, &rec)
 	if err != nil {
 		return sendErr(err)
 	}
 
-	err = s.updateThemes(ctx, tx, rec.postId, themes)
-	if err != nil {
-		return sendErr(err)
+	if replaceThemes {
+		err = s.updateThemes(ctx, tx, rec.postId, themes)
+		if err != nil {
+			return sendErr(err)
+		}
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		return sendErr(err)
+	if len(addThemes) != 0 {
+		err = s.addThemes(ctx, tx, rec.postId, addThemes)
+		if err != nil {
+			return sendErr(err)
+		}
+	}
+
+	if len(removeThemes) != 0 {
+		err = s.removeThemes(ctx, tx, rec.postId, removeThemes)
+		if err != nil {
+			return sendErr(err)
+		}
 	}
 
 	return nil
 }
 
-// makePostRec creates a record for new post information
+// makePostRec locks the post and creates a record for new post information.
+// Only fields named in the mask are taken from the arguments, format and
+// rendered content are replaced together with the content
 func (s *Storage) makePostRec(
 	ctx context.Context,
+	tx *sql.Tx,
 	postId int,
 	userId int,
 	header string,
 	content string,
+	format models.ContentFormat,
+	rendered models.RenderedContent,
+	mask models.UpdateMask,
+	version int,
 ) (record, error) {
 	const (
 		op = "postgres.newPostRec"
@@ -320,7 +490,7 @@ func (s *Storage) makePostRec(
 		return record{}, fail(op, err)
 	}
 
-	rec, err := s.post(ctx, postId)
+	rec, err := s.post(ctx, tx, postId)
 	if err != nil {
 		return sendErr(err)
 	}
@@ -328,18 +498,24 @@ func (s *Storage) makePostRec(
 	if !s.isCreator(rec.userId, userId) {
 		return sendErr(storage.ErrNotCreator)
 	}
+	if !s.isVersion(rec.version, version) {
+		return sendErr(storage.ErrConflict)
+	}
 
-	if header != "" {
+	if mask.Header {
 		rec.header = header
 	}
-	if content != "" {
+	if mask.Content {
 		rec.content = content
+		rec.format = format
+		rec.rendered = rendered
 	}
 
 	return rec, nil
 }
 
-// updatePost updates post records with replacing content and header
+// updatePost updates post records with replacing header, content and
+// the rendered content
 func (s *Storage) updatePost(
 	ctx context.Context,
 	tx *sql.Tx,
@@ -348,10 +524,23 @@ func (s *Storage) updatePost(
 	const (
 		op        = "postgres.updatePost"
 		updtQuery = `
-			UPDATE posts SET header=$1, content=$2 WHERE post_id=$3`
+			UPDATE posts SET header=$1, content=$2, content_format=$3, content_html=$4,
+				excerpt=$5, word_count=$6, reading_seconds=$7, version=version+1
+			WHERE post_id=$8`
 	)
 
-	_, err := tx.ExecContext(ctx, updtQuery, post.header, post.content, post.postId)
+	_, err := tx.ExecContext(
+		ctx,
+		updtQuery,
+		post.header,
+		post.content,
+		post.format,
+		post.rendered.HTML,
+		post.rendered.Excerpt,
+		post.rendered.WordCount,
+		int(post.rendered.ReadingTime.Seconds()),
+		post.postId,
+	)
 	if err != nil {
 		return fail(op, err)
 	}
@@ -391,29 +580,30 @@ func (s *Storage) updateThemes(
 	return nil
 }
 
-func (s *Storage) Delete(
+// addThemes adds the themes to the post. Themes which the post already has are skipped
+func (s *Storage) addThemes(
 	ctx context.Context,
+	tx *sql.Tx,
 	postId int,
-	userId int,
+	themes []string,
 ) error {
-	const op = "postgres.Delete"
+	const (
+		op         = "postgres.addThemes"
+		insrtQuery = `
+			INSERT INTO post_theme(post_id, theme_id)
+			SELECT $1, UNNEST($2::INT[])
+			ON CONFLICT DO NOTHING;`
+	)
 	sendErr := func(err error) error {
 		return fail(op, err)
 	}
 
-	ctx, cncl := context.WithCancel(ctx)
-	defer cncl()
-
-	rec, err := s.post(ctx, postId)
+	thmIds, err := s.loadThemeIds(ctx, tx, themes)
 	if err != nil {
 		return sendErr(err)
 	}
 
-	if !s.isCreator(rec.userId, userId) {
-		return sendErr(storage.ErrNotCreator)
-	}
-
-	err = s.delete(ctx, postId)
+	_, err = tx.ExecContext(ctx, insrtQuery, postId, pq.Array(toInt64s(thmIds)))
 	if err != nil {
 		return sendErr(err)
 	}
@@ -421,30 +611,69 @@ func (s *Storage) Delete(
 	return nil
 }
 
-// delete deletes all information related to post with the postId
-func (s *Storage) delete(
+// removeThemes removes the themes from the post. Themes are resolved
+// through aliases, unknown themes are skipped
+func (s *Storage) removeThemes(
 	ctx context.Context,
+	tx *sql.Tx,
 	postId int,
+	themes []string,
 ) error {
 	const (
-		op = "postgres.delete"
+		op       = "postgres.removeThemes"
+		dltQuery = `
+			DELETE FROM post_theme
+			WHERE post_id = $1 AND theme_id IN (
+				SELECT theme_id FROM themes WHERE theme_name = ANY($2)
+				UNION
+				SELECT theme_id FROM theme_aliases WHERE alias = ANY($2)
+			);`
 	)
+
+	_, err := tx.ExecContext(ctx, dltQuery, postId, pq.Array(themes))
+	if err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// Delete moves the post to trash if the user is its creator and the post has
+// the expected version. Zero version skips the check. [storage.ErrConflict] is
+// returned on version mismatch
+func (s *Storage) Delete(
+	ctx context.Context,
+	postId int,
+	userId int,
+	version int,
+) error {
+	const op = "postgres.Delete"
 	sendErr := func(err error) error {
 		return fail(op, err)
 	}
 
+	ctx, cncl := context.WithCancel(ctx)
+	defer cncl()
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return sendErr(err)
 	}
 	defer tx.Rollback()
 
-	err = s.deletePost(ctx, tx, postId)
+	rec, err := s.post(ctx, tx, postId)
 	if err != nil {
 		return sendErr(err)
 	}
 
-	err = s.deleteRelations(ctx, tx, postId)
+	if !s.isCreator(rec.userId, userId) {
+		return sendErr(storage.ErrNotCreator)
+	}
+	if !s.isVersion(rec.version, version) {
+		return sendErr(storage.ErrConflict)
+	}
+
+	err = s.trashPost(ctx, tx, postId)
 	if err != nil {
 		return sendErr(err)
 	}
@@ -457,20 +686,22 @@ func (s *Storage) delete(
 	return nil
 }
 
-// deletePost deletes post with the postId
-func (s *Storage) deletePost(
+// trashPost moves the post with the postId to trash. Trashed post is
+// hidden from reads until it is restored or purged
+func (s *Storage) trashPost(
 	ctx context.Context,
 	tx *sql.Tx,
 	postId int,
 ) error {
 	const (
-		op       = "postgres.deletePost"
-		dltQuery = `
-			DELETE FROM posts WHERE post_id=$1;
+		op        = "postgres.trashPost"
+		updtQuery = `
+			UPDATE posts SET deleted_at=NOW(), version=version+1
+			WHERE post_id=$1 AND deleted_at IS NULL;
 		`
 	)
 
-	_, err := tx.ExecContext(ctx, dltQuery, postId)
+	_, err := tx.ExecContext(ctx, updtQuery, postId)
 	if err != nil {
 		return fail(op, err)
 	}
@@ -486,7 +717,12 @@ func (s *Storage) fetchAllIds(
 	login *string,
 	header *string,
 	content *string,
+	format models.ContentFormat,
+	rendered *models.RenderedContent,
 	themes []string,
+	status models.PostStatus,
+	publishAt time.Time,
+	visibility models.Visibility,
 ) (postId int, thmIds []int, err error) {
 	const op = "fetchAllIds"
 
@@ -499,7 +735,19 @@ func (s *Storage) fetchAllIds(
 		return sendErr(err)
 	}
 
-	postId, err = s.savePost(ctx, tx, userId, login, header, content)
+	postId, err = s.savePost(
+		ctx,
+		tx,
+		userId,
+		login,
+		header,
+		content,
+		format,
+		rendered,
+		status,
+		publishAt,
+		visibility,
+	)
 	if err != nil {
 		return sendErr(err)
 	}
@@ -507,8 +755,10 @@ func (s *Storage) fetchAllIds(
 	return postId, thmIds, nil
 }
 
-// loadThemeIds loads id by theme names from slice. If name does not exist
-// in database new theme is created and the id of the new theme is returned
+// loadThemeIds loads id by theme names from slice. Aliases are resolved to
+// their canonical themes. If name does not exist in database new theme is
+// created and the id of the new theme is returned. Names must be normalized,
+// returned ids are unique
 func (*Storage) loadThemeIds(
 	ctx context.Context,
 	tx *sql.Tx,
@@ -518,11 +768,21 @@ func (*Storage) loadThemeIds(
 		op               = "postgres.LoadThemeIds"
 		selectThemeQuery = `
 			SELECT theme_id
-			FROM themes
-			WHERE theme_name=$1;`
+			FROM (
+				SELECT theme_id, 0 AS priority
+				FROM theme_aliases
+				WHERE alias=$1
+				UNION ALL
+				SELECT theme_id, 1 AS priority
+				FROM themes
+				WHERE theme_name=$1
+			) AS t
+			ORDER BY priority
+			LIMIT 1;`
 		insertNewTheme = `
 			INSERT INTO themes(theme_name) 
 			VALUES ($1)
+			ON CONFLICT (theme_name) DO UPDATE SET theme_name=EXCLUDED.theme_name
 			RETURNING theme_id`
 	)
 
@@ -542,9 +802,10 @@ func (*Storage) loadThemeIds(
 	}
 	defer insrtStmt.Close()
 
-	themeIds = make([]int, len(themes))
+	themeIds = make([]int, 0, len(themes))
+	seen := make(map[int]struct{}, len(themes))
 	var id int
-	for i, theme := range themes {
+	for _, theme := range themes {
 		select {
 		case <-ctx.Done():
 			return sendErr(ctx.Err())
@@ -563,35 +824,59 @@ func (*Storage) loadThemeIds(
 			}
 		}
 
-		themeIds[i] = id
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		themeIds = append(themeIds, id)
 	}
 
 	return themeIds, nil
 }
 
-// post returns record with posts' information
+// post locks the post which is not in trash until the end of the transaction
+// and returns record with its information
 func (s *Storage) post(
 	ctx context.Context,
+	tx *sql.Tx,
 	postId int,
 ) (record, error) {
 	const (
 		op        = "postgres.post"
 		slctQuery = `
-			SELECT post_id, user_id, header, content
+			SELECT post_id, user_id, header, content, content_format, content_html,
+				excerpt, word_count, reading_seconds, version
 			FROM posts
-			WHERE post_id = $1;
+			WHERE post_id = $1 AND deleted_at IS NULL
+			FOR UPDATE;
 		`
 	)
-	var rec record
+	var (
+		rec            record
+		readingSeconds int
+	)
 
-	row := s.db.QueryRowContext(ctx, slctQuery, postId)
-	if err := row.Scan(&rec.postId, &rec.userId, &rec.header, &rec.content); err != nil {
+	row := tx.QueryRowContext(ctx, slctQuery, postId)
+	err := row.Scan(
+		&rec.postId,
+		&rec.userId,
+		&rec.header,
+		&rec.content,
+		&rec.format,
+		&rec.rendered.HTML,
+		&rec.rendered.Excerpt,
+		&rec.rendered.WordCount,
+		&readingSeconds,
+		&rec.version,
+	)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return rec, fail(op, storage.ErrNotFound)
 		}
 
 		return rec, fail(op, err)
 	}
+	rec.rendered.ReadingTime = time.Duration(readingSeconds) * time.Second
 
 	return rec, nil
 }
@@ -621,6 +906,12 @@ func (s *Storage) isCreator(recUserId, userId int) bool {
 	return recUserId == userId
 }
 
+// isVersion checks does the record have the expected version.
+// Zero expected version matches any version
+func (s *Storage) isVersion(recVersion, version int) bool {
+	return version == 0 || recVersion == version
+}
+
 // Stop stops working of storage entity
 func (s *Storage) Stop() error {
 	const (
@@ -0,0 +1,399 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	"github.com/IlianBuh/Post-service/internal/storage"
+	"github.com/lib/pq"
+)
+
+// slctPostQuery selects posts with aggregated theme names. Every query built
+// on top of it must group rows by p.post_id, skip trashed and unpublished
+// posts unless they are requested and check visibility of posts with post_visible
+const slctPostQuery = `
+	SELECT p.post_id, p.user_id, p.login, p.header, p.content, p.created_at,
+		COALESCE(
+			ARRAY_AGG(t.theme_name ORDER BY t.theme_name) FILTER (WHERE t.theme_name IS NOT NULL),
+			'{}'
+		),
+		p.deleted_at, p.status, p.publish_at, p.visibility, p.version,
+		p.content_format, p.content_html, p.excerpt, p.word_count, p.reading_seconds,
+		p.comments_count,
+		COALESCE((SELECT ps.views FROM post_stats ps WHERE ps.post_id = p.post_id), 0)
+	FROM posts p
+	LEFT JOIN post_theme pt ON pt.post_id = p.post_id
+	LEFT JOIN themes t ON t.theme_id = pt.theme_id`
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// Post returns post with the postId and all its themes if the viewer can read
+// it. Trashed post is not found, unpublished post is found only by its author
+func (s *Storage) Post(
+	ctx context.Context,
+	viewer models.Viewer,
+	postId int,
+) (models.Post, error) {
+	const (
+		op        = "postgres.Post"
+		slctQuery = slctPostQuery + `
+			WHERE p.post_id = $1 AND p.deleted_at IS NULL
+				AND (p.status = 'published' OR p.user_id = $2)
+				AND post_visible(p.visibility, p.user_id, $2, $3, FALSE)
+			GROUP BY p.post_id;`
+	)
+
+	post, err := scanPost(s.db.QueryRowContext(
+		ctx,
+		slctQuery,
+		postId,
+		viewer.Id,
+		pq.Array(toInt64s(viewer.Following)),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Post{}, fail(op, storage.ErrNotFound)
+		}
+
+		return models.Post{}, fail(op, err)
+	}
+
+	return post, nil
+}
+
+// Posts returns posts which ids are in the list. Missing, trashed and hidden
+// from the viewer posts are skipped, order of the result is not defined
+func (s *Storage) Posts(
+	ctx context.Context,
+	viewer models.Viewer,
+	postIds []int,
+) ([]models.Post, error) {
+	const op = "postgres.Posts"
+
+	posts, err := s.posts(ctx, s.db, viewer, postIds)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return posts, nil
+}
+
+// posts returns posts which ids are in the list with the querier, so
+// they can be read in the same transaction with the ids
+func (s *Storage) posts(
+	ctx context.Context,
+	q querier,
+	viewer models.Viewer,
+	postIds []int,
+) ([]models.Post, error) {
+	const (
+		op        = "postgres.posts"
+		slctQuery = slctPostQuery + `
+			WHERE p.post_id = ANY($1) AND p.deleted_at IS NULL
+				AND (p.status = 'published' OR p.user_id = $2)
+				AND post_visible(p.visibility, p.user_id, $2, $3, FALSE)
+			GROUP BY p.post_id;`
+	)
+
+	rows, err := q.QueryContext(
+		ctx,
+		slctQuery,
+		pq.Array(toInt64s(postIds)),
+		viewer.Id,
+		pq.Array(toInt64s(viewer.Following)),
+	)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	posts, err := scanPosts(rows, len(postIds))
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return posts, nil
+}
+
+// PostsByUser returns at most limit posts of the user listed for the viewer
+// ordered from the newest to the oldest. Only posts placed after the cursor
+// are returned
+func (s *Storage) PostsByUser(
+	ctx context.Context,
+	viewer models.Viewer,
+	userId int,
+	after cursor.Cursor,
+	limit int,
+) ([]models.Post, error) {
+	const (
+		op        = "postgres.PostsByUser"
+		slctQuery = slctPostQuery + `
+			WHERE p.user_id = $1
+				AND p.deleted_at IS NULL AND p.status = 'published'
+				AND post_visible(p.visibility, p.user_id, $5, $6, TRUE)
+				AND ($2::TIMESTAMPTZ IS NULL OR (p.created_at, p.post_id) < ($2, $3))
+			GROUP BY p.post_id
+			ORDER BY p.created_at DESC, p.post_id DESC
+			LIMIT $4;`
+	)
+
+	afterTime, afterId := keysetArgs(after)
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		slctQuery,
+		userId,
+		afterTime,
+		afterId,
+		limit,
+		viewer.Id,
+		pq.Array(toInt64s(viewer.Following)),
+	)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	posts, err := scanPosts(rows, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return posts, nil
+}
+
+// PostsByThemes returns at most limit posts ordered from the newest to the
+// oldest which are placed after the cursor and have any of the themes, or all
+// of them if matchAll is set. Aliases are matched as their canonical themes.
+// Zero userId disables filtering by author. Only posts listed for the viewer
+// are returned
+func (s *Storage) PostsByThemes(
+	ctx context.Context,
+	viewer models.Viewer,
+	themes []string,
+	matchAll bool,
+	userId int,
+	after cursor.Cursor,
+	limit int,
+) ([]models.Post, error) {
+	const (
+		op = "postgres.PostsByThemes"
+		// wanted resolves requested names and aliases to theme ids,
+		// unknown names get NULL id
+		slctQuery = `
+			WITH wanted AS (
+				SELECT COALESCE(a.theme_id, wt.theme_id) AS theme_id
+				FROM UNNEST($1::TEXT[]) AS n(name)
+				LEFT JOIN theme_aliases a ON a.alias = n.name
+				LEFT JOIN themes wt ON wt.theme_name = n.name
+			)` + slctPostQuery + `
+			WHERE p.post_id IN (
+					SELECT mpt.post_id
+					FROM post_theme mpt
+					WHERE mpt.theme_id IN (SELECT theme_id FROM wanted)
+					GROUP BY mpt.post_id
+					HAVING NOT $2::BOOLEAN OR (
+						COUNT(DISTINCT mpt.theme_id) = (SELECT COUNT(DISTINCT theme_id) FROM wanted)
+						AND NOT EXISTS (SELECT 1 FROM wanted WHERE theme_id IS NULL)
+					)
+				)
+				AND ($3::INT = 0 OR p.user_id = $3)
+				AND p.deleted_at IS NULL AND p.status = 'published'
+				AND post_visible(p.visibility, p.user_id, $7, $8, TRUE)
+				AND ($4::TIMESTAMPTZ IS NULL OR (p.created_at, p.post_id) < ($4, $5))
+			GROUP BY p.post_id
+			ORDER BY p.created_at DESC, p.post_id DESC
+			LIMIT $6;`
+	)
+
+	afterTime, afterId := keysetArgs(after)
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		slctQuery,
+		pq.Array(themes),
+		matchAll,
+		userId,
+		afterTime,
+		afterId,
+		limit,
+		viewer.Id,
+		pq.Array(toInt64s(viewer.Following)),
+	)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	posts, err := scanPosts(rows, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return posts, nil
+}
+
+// Feed returns at most limit posts of the authors ordered from the newest to
+// the oldest. Only posts placed after the cursor are returned. If themes are
+// not empty, posts must have any of them. Posts having any of excluded themes
+// and posts with excluded ids are skipped. Aliases are matched as their
+// canonical themes. Only posts listed for the viewer are returned
+func (s *Storage) Feed(
+	ctx context.Context,
+	viewer models.Viewer,
+	authorIds []int,
+	themes []string,
+	excludeThemes []string,
+	excludePostIds []int,
+	after cursor.Cursor,
+	limit int,
+) ([]models.Post, error) {
+	const (
+		op        = "postgres.Feed"
+		slctQuery = `
+			WITH wanted AS (
+				SELECT theme_id FROM themes WHERE theme_name = ANY($2)
+				UNION
+				SELECT theme_id FROM theme_aliases WHERE alias = ANY($2)
+			),
+			muted AS (
+				SELECT theme_id FROM themes WHERE theme_name = ANY($3)
+				UNION
+				SELECT theme_id FROM theme_aliases WHERE alias = ANY($3)
+			)` + slctPostQuery + `
+			WHERE p.user_id = ANY($1)
+				AND p.deleted_at IS NULL AND p.status = 'published'
+				AND post_visible(p.visibility, p.user_id, $8, $9, TRUE)
+				AND (
+					COALESCE(CARDINALITY($2::TEXT[]), 0) = 0
+					OR EXISTS (
+						SELECT 1
+						FROM post_theme fpt
+						WHERE fpt.post_id = p.post_id AND fpt.theme_id IN (SELECT theme_id FROM wanted)
+					)
+				)
+				AND NOT EXISTS (
+					SELECT 1
+					FROM post_theme xpt
+					WHERE xpt.post_id = p.post_id AND xpt.theme_id IN (SELECT theme_id FROM muted)
+				)
+				AND p.post_id <> ALL($4)
+				AND ($5::TIMESTAMPTZ IS NULL OR (p.created_at, p.post_id) < ($5, $6))
+			GROUP BY p.post_id
+			ORDER BY p.created_at DESC, p.post_id DESC
+			LIMIT $7;`
+	)
+
+	afterTime, afterId := keysetArgs(after)
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		slctQuery,
+		pq.Array(toInt64s(authorIds)),
+		pq.Array(themes),
+		pq.Array(excludeThemes),
+		pq.Array(toInt64s(excludePostIds)),
+		afterTime,
+		afterId,
+		limit,
+		viewer.Id,
+		pq.Array(toInt64s(viewer.Following)),
+	)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	posts, err := scanPosts(rows, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return posts, nil
+}
+
+// toInt64s converts ids to the type supported by pq.Array. Result is never nil
+func toInt64s(ids []int) []int64 {
+	res := make([]int64, len(ids))
+	for i, id := range ids {
+		res[i] = int64(id)
+	}
+
+	return res
+}
+
+// keysetArgs converts cursor to query arguments. Zero cursor is converted to NULL
+func keysetArgs(c cursor.Cursor) (sql.NullTime, int) {
+	if c.IsZero() {
+		return sql.NullTime{}, 0
+	}
+
+	return sql.NullTime{Time: c.CreatedAt, Valid: true}, c.PostId
+}
+
+// scanPosts scans all rows selected by slctPostQuery
+func scanPosts(rows *sql.Rows, capacity int) ([]models.Post, error) {
+	posts := make([]models.Post, 0, capacity)
+
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// scanPost scans one row selected by slctPostQuery into the post model
+func scanPost(row scanner) (models.Post, error) {
+	var (
+		post           models.Post
+		deletedAt      sql.NullTime
+		publishAt      sql.NullTime
+		readingSeconds int
+	)
+
+	err := row.Scan(
+		&post.Id,
+		&post.UserId,
+		&post.Login,
+		&post.Header,
+		&post.Content,
+		&post.CreatedAt,
+		pq.Array(&post.Themes),
+		&deletedAt,
+		&post.Status,
+		&publishAt,
+		&post.Visibility,
+		&post.Version,
+		&post.ContentFormat,
+		&post.Rendered.HTML,
+		&post.Rendered.Excerpt,
+		&post.Rendered.WordCount,
+		&readingSeconds,
+		&post.CommentsCount,
+		&post.Views,
+	)
+	if err != nil {
+		return models.Post{}, err
+	}
+	post.DeletedAt = deletedAt.Time
+	post.PublishAt = publishAt.Time
+	post.Rendered.ReadingTime = time.Duration(readingSeconds) * time.Second
+
+	return post, nil
+}
@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	"github.com/IlianBuh/Post-service/internal/storage"
+	"github.com/IlianBuh/Post-service/internal/storage/events"
+)
+
+// Publish publishes the draft or scheduled post right now if publishAt is not
+// in the future, otherwise the post is scheduled to be published at publishAt.
+// Returns [storage.ErrNotFound], [storage.ErrNotCreator] or
+// [storage.ErrPublished] if the post is already published
+func (s *Storage) Publish(
+	ctx context.Context,
+	postId int,
+	userId int,
+	publishAt time.Time,
+) error {
+	const (
+		op        = "postgres.Publish"
+		slctQuery = `
+			SELECT user_id, status
+			FROM posts
+			WHERE post_id = $1 AND deleted_at IS NULL
+			FOR UPDATE;`
+		schdlQuery = `
+			UPDATE posts SET status='scheduled', publish_at=$2, version=version+1 WHERE post_id=$1;`
+	)
+	sendErr := func(err error) error {
+		return fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	var (
+		recUserId int
+		status    models.PostStatus
+	)
+	err = tx.QueryRowContext(ctx, slctQuery, postId).Scan(&recUserId, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sendErr(storage.ErrNotFound)
+		}
+
+		return sendErr(err)
+	}
+
+	if !s.isCreator(recUserId, userId) {
+		return sendErr(storage.ErrNotCreator)
+	}
+	if status == models.StatusPublished {
+		return sendErr(storage.ErrPublished)
+	}
+
+	if publishAt.After(time.Now()) {
+		_, err = tx.ExecContext(ctx, schdlQuery, postId, publishAt)
+	} else {
+		err = s.publish(ctx, tx, postId)
+	}
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// PublishDue publishes at most limit scheduled posts which publishing time has
+// come. Event about every published post is saved in the same transaction.
+// Returns number of published posts
+func (s *Storage) PublishDue(
+	ctx context.Context,
+	limit int,
+) (int, error) {
+	const (
+		op        = "postgres.PublishDue"
+		slctQuery = `
+			SELECT post_id
+			FROM posts
+			WHERE status = 'scheduled' AND publish_at <= NOW() AND deleted_at IS NULL
+			ORDER BY publish_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED;`
+	)
+	sendErr := func(err error) (int, error) {
+		return 0, fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, slctQuery, limit)
+	if err != nil {
+		return sendErr(err)
+	}
+	postIds, err := scanIds(rows)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	for _, postId := range postIds {
+		if err = s.publish(ctx, tx, postId); err != nil {
+			return sendErr(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return len(postIds), nil
+}
+
+// publish marks the post as published right now and saves
+// events about the new post and users mentioned in it
+func (s *Storage) publish(
+	ctx context.Context,
+	tx *sql.Tx,
+	postId int,
+) error {
+	const (
+		op         = "postgres.publish"
+		pblshQuery = `
+			UPDATE posts SET status='published', publish_at=NULL, created_at=NOW(), version=version+1
+			WHERE post_id=$1
+			RETURNING user_id, login, header, excerpt, created_at, visibility;`
+	)
+
+	var (
+		userId      int
+		login       string
+		header      string
+		excerpt     string
+		publishedAt time.Time
+		visibility  string
+	)
+	err := tx.QueryRowContext(ctx, pblshQuery, postId).Scan(
+		&userId,
+		&login,
+		&header,
+		&excerpt,
+		&publishedAt,
+		&visibility,
+	)
+	if err != nil {
+		return fail(op, err)
+	}
+
+	payload, err := events.CollectEventPayload(
+		postId,
+		userId,
+		login,
+		header,
+		excerpt,
+		publishedAt,
+		visibility,
+	)
+	if err != nil {
+		return fail(op, err)
+	}
+
+	eventId := events.CollectPostEventId(events.TypeCteated, postId)
+	if err = s.saveEvent(ctx, tx, eventId, events.TypeCteated, payload); err != nil {
+		return fail(op, err)
+	}
+
+	mentions, err := s.mentions(ctx, tx, postId)
+	if err != nil {
+		return fail(op, err)
+	}
+	if err = s.notifyMentioned(ctx, tx, postId, mentions, publishedAt); err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// Unpublished returns at most limit drafts and scheduled posts of the user
+// ordered from the newest to the oldest. Only posts placed after the cursor
+// are returned
+func (s *Storage) Unpublished(
+	ctx context.Context,
+	userId int,
+	after cursor.Cursor,
+	limit int,
+) ([]models.Post, error) {
+	const (
+		op        = "postgres.Unpublished"
+		slctQuery = slctPostQuery + `
+			WHERE p.user_id = $1
+				AND p.deleted_at IS NULL AND p.status <> 'published'
+				AND ($2::TIMESTAMPTZ IS NULL OR (p.created_at, p.post_id) < ($2, $3))
+			GROUP BY p.post_id
+			ORDER BY p.created_at DESC, p.post_id DESC
+			LIMIT $4;`
+	)
+
+	afterTime, afterId := keysetArgs(after)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, userId, afterTime, afterId, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	posts, err := scanPosts(rows, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return posts, nil
+}
@@ -0,0 +1,303 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	"github.com/IlianBuh/Post-service/internal/storage"
+	"github.com/IlianBuh/Post-service/internal/storage/events"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// React sets reaction of the kind of the user to the published post, previous
+// reaction of the user is replaced. Counters of the post are updated and event
+// about the new reaction is saved in the same transaction, repeated reaction
+// of the same kind changes nothing. Returns counters of the post or
+// [storage.ErrNotFound] if there is no such published post
+func (s *Storage) React(
+	ctx context.Context,
+	postId int,
+	userId int,
+	kind string,
+) ([]models.ReactionCount, error) {
+	const (
+		op        = "postgres.React"
+		slctQuery = `
+			SELECT user_id
+			FROM posts
+			WHERE post_id = $1 AND deleted_at IS NULL AND status = 'published'
+			FOR KEY SHARE;`
+		insrtQuery = `
+			INSERT INTO post_reactions(post_id, user_id, kind)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (post_id, user_id) DO NOTHING
+			RETURNING reacted_at;`
+		slctKindQuery = `
+			SELECT kind
+			FROM post_reactions
+			WHERE post_id = $1 AND user_id = $2
+			FOR UPDATE;`
+		updtQuery = `
+			UPDATE post_reactions SET kind=$3, reacted_at=NOW()
+			WHERE post_id = $1 AND user_id = $2
+			RETURNING reacted_at;`
+	)
+	sendErr := func(err error) ([]models.ReactionCount, error) {
+		return nil, fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	var authorId int
+	if err = tx.QueryRowContext(ctx, slctQuery, postId).Scan(&authorId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sendErr(storage.ErrNotFound)
+		}
+
+		return sendErr(err)
+	}
+
+	var (
+		reactedAt time.Time
+		oldKind   string
+	)
+	// reaction removed concurrently between the insert and the
+	// select is inserted again on the next iteration
+	for {
+		err = tx.QueryRowContext(ctx, insrtQuery, postId, userId, kind).Scan(&reactedAt)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return sendErr(err)
+		}
+
+		err = tx.QueryRowContext(ctx, slctKindQuery, postId, userId).Scan(&oldKind)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return sendErr(err)
+		}
+	}
+
+	if oldKind != kind {
+		if oldKind != "" {
+			if err = tx.QueryRowContext(ctx, updtQuery, postId, userId, kind).Scan(&reactedAt); err != nil {
+				return sendErr(err)
+			}
+			if err = s.addReactionCount(ctx, tx, postId, oldKind, -1); err != nil {
+				return sendErr(err)
+			}
+		}
+		if err = s.addReactionCount(ctx, tx, postId, kind, 1); err != nil {
+			return sendErr(err)
+		}
+
+		payload, err := events.CollectReactionPayload(postId, authorId, userId, kind, reactedAt)
+		if err != nil {
+			return sendErr(err)
+		}
+
+		eventId := events.CollectReactionEventId(postId, userId, reactedAt)
+		if err = s.saveEvent(ctx, tx, eventId, events.TypeReacted, payload); err != nil {
+			return sendErr(err)
+		}
+	}
+
+	counts, err := s.reactionCounts(ctx, tx, postId)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return counts, nil
+}
+
+// Unreact removes reaction of the user to the post and updates counters
+// of the post in the same transaction. Returns counters of the post, missing
+// reaction is not an error
+func (s *Storage) Unreact(
+	ctx context.Context,
+	postId int,
+	userId int,
+) ([]models.ReactionCount, error) {
+	const (
+		op       = "postgres.Unreact"
+		dltQuery = `
+			DELETE FROM post_reactions
+			WHERE post_id = $1 AND user_id = $2
+			RETURNING kind;`
+	)
+	sendErr := func(err error) ([]models.ReactionCount, error) {
+		return nil, fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	var kind string
+	err = tx.QueryRowContext(ctx, dltQuery, postId, userId).Scan(&kind)
+	switch {
+	case err == nil:
+		if err = s.addReactionCount(ctx, tx, postId, kind, -1); err != nil {
+			return sendErr(err)
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return sendErr(err)
+	}
+
+	counts, err := s.reactionCounts(ctx, tx, postId)
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return counts, nil
+}
+
+// Reactors returns at most limit reactions to the post ordered from the
+// newest. Empty kind means reactions of any kind. Only reactions placed after
+// the cursor are returned, the cursor keeps id of the user in place of post id
+func (s *Storage) Reactors(
+	ctx context.Context,
+	postId int,
+	kind string,
+	after cursor.Cursor,
+	limit int,
+) ([]models.Reaction, error) {
+	const (
+		op        = "postgres.Reactors"
+		slctQuery = `
+			SELECT post_id, user_id, kind, reacted_at
+			FROM post_reactions
+			WHERE post_id = $1
+				AND ($2 = '' OR kind = $2)
+				AND ($3::TIMESTAMPTZ IS NULL OR (reacted_at, user_id) < ($3, $4))
+			ORDER BY reacted_at DESC, user_id DESC
+			LIMIT $5;`
+	)
+
+	afterTime, afterId := keysetArgs(after)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, postId, kind, afterTime, afterId, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	reactions := make([]models.Reaction, 0, limit)
+	for rows.Next() {
+		var r models.Reaction
+		if err = rows.Scan(&r.PostId, &r.UserId, &r.Kind, &r.ReactedAt); err != nil {
+			return nil, fail(op, err)
+		}
+
+		reactions = append(reactions, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return reactions, nil
+}
+
+// ReactionCounts returns non-zero counters of reactions to the post
+// ordered from the most popular kind
+func (s *Storage) ReactionCounts(
+	ctx context.Context,
+	postId int,
+) ([]models.ReactionCount, error) {
+	const op = "postgres.ReactionCounts"
+
+	counts, err := s.reactionCounts(ctx, s.db, postId)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return counts, nil
+}
+
+// addReactionCount adds delta to the counter of reactions of the kind
+func (s *Storage) addReactionCount(
+	ctx context.Context,
+	tx *sql.Tx,
+	postId int,
+	kind string,
+	delta int,
+) error {
+	const (
+		op         = "postgres.addReactionCount"
+		upsrtQuery = `
+			INSERT INTO post_reaction_counts(post_id, kind, count)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (post_id, kind) DO UPDATE
+			SET count = post_reaction_counts.count + EXCLUDED.count;`
+	)
+
+	if _, err := tx.ExecContext(ctx, upsrtQuery, postId, kind, delta); err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// reactionCounts returns non-zero counters of reactions to the post
+func (s *Storage) reactionCounts(
+	ctx context.Context,
+	q querier,
+	postId int,
+) ([]models.ReactionCount, error) {
+	const (
+		op        = "postgres.reactionCounts"
+		slctQuery = `
+			SELECT kind, count
+			FROM post_reaction_counts
+			WHERE post_id = $1 AND count > 0
+			ORDER BY count DESC, kind;`
+	)
+
+	rows, err := q.QueryContext(ctx, slctQuery, postId)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	var counts []models.ReactionCount
+	for rows.Next() {
+		var c models.ReactionCount
+		if err = rows.Scan(&c.Kind, &c.Count); err != nil {
+			return nil, fail(op, err)
+		}
+
+		counts = append(counts, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return counts, nil
+}
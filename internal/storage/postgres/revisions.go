@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/storage"
+	"github.com/lib/pq"
+)
+
+// saveRevision saves the current state of the post with postId as its'
+// next revision. It must be called before the post is changed
+func (s *Storage) saveRevision(
+	ctx context.Context,
+	tx *sql.Tx,
+	postId int,
+) error {
+	const (
+		op         = "postgres.saveRevision"
+		insrtQuery = `
+			INSERT INTO post_revisions(post_id, revision, header, content, themes, content_format)
+			SELECT p.post_id,
+				COALESCE((SELECT MAX(revision) FROM post_revisions WHERE post_id = p.post_id), 0) + 1,
+				p.header,
+				p.content,
+				ARRAY(
+					SELECT t.theme_name
+					FROM post_theme pt
+					JOIN themes t ON t.theme_id = pt.theme_id
+					WHERE pt.post_id = p.post_id
+					ORDER BY t.theme_name
+				),
+				p.content_format
+			FROM posts p
+			WHERE p.post_id = $1;`
+	)
+
+	if _, err := tx.ExecContext(ctx, insrtQuery, postId); err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// Revisions returns at most limit revisions of the post, the newest first.
+// Only revisions older than before are returned, zero before means no bound
+func (s *Storage) Revisions(
+	ctx context.Context,
+	postId int,
+	before int,
+	limit int,
+) ([]models.Revision, error) {
+	const (
+		op        = "postgres.Revisions"
+		slctQuery = `
+			SELECT post_id, revision, header, content, themes, created_at, content_format
+			FROM post_revisions
+			WHERE post_id = $1 AND ($2 = 0 OR revision < $2)
+			ORDER BY revision DESC
+			LIMIT $3;`
+	)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, postId, before, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	revisions := make([]models.Revision, 0, limit)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fail(op, err)
+		}
+
+		revisions = append(revisions, rev)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return revisions, nil
+}
+
+// Revision returns the revision of the post
+func (s *Storage) Revision(
+	ctx context.Context,
+	postId int,
+	revision int,
+) (models.Revision, error) {
+	const (
+		op        = "postgres.Revision"
+		slctQuery = `
+			SELECT post_id, revision, header, content, themes, created_at, content_format
+			FROM post_revisions
+			WHERE post_id = $1 AND revision = $2;`
+	)
+
+	rev, err := scanRevision(s.db.QueryRowContext(ctx, slctQuery, postId, revision))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Revision{}, fail(op, storage.ErrNotFound)
+		}
+
+		return models.Revision{}, fail(op, err)
+	}
+
+	return rev, nil
+}
+
+// scanRevision scans one row of post_revisions into the revision model
+func scanRevision(row scanner) (models.Revision, error) {
+	var rev models.Revision
+
+	err := row.Scan(
+		&rev.PostId,
+		&rev.Revision,
+		&rev.Header,
+		&rev.Content,
+		pq.Array(&rev.Themes),
+		&rev.CreatedAt,
+		&rev.ContentFormat,
+	)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	return rev, nil
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	"github.com/lib/pq"
+)
+
+// Search returns at most limit posts matching the full-text query ordered by
+// rank. Only posts placed after the cursor are returned. Empty themes and zero
+// userId disable filtering by themes and author respectively. Only posts
+// listed for the viewer are returned
+func (s *Storage) Search(
+	ctx context.Context,
+	viewer models.Viewer,
+	query string,
+	themes []string,
+	userId int,
+	after cursor.Rank,
+	limit int,
+) ([]models.SearchHit, error) {
+	const (
+		op        = "postgres.Search"
+		slctQuery = `
+			WITH q AS (
+				SELECT websearch_to_tsquery('english', $1) AS query
+			),
+			ranked AS (
+				SELECT p.post_id, ts_rank(p.search_vector, q.query) AS rank
+				FROM posts p, q
+				WHERE p.search_vector @@ q.query
+					AND p.deleted_at IS NULL AND p.status = 'published'
+					AND post_visible(p.visibility, p.user_id, $7, $8, TRUE)
+					AND (
+						COALESCE(CARDINALITY($2::TEXT[]), 0) = 0
+						OR EXISTS (
+							SELECT 1
+							FROM post_theme spt
+							JOIN themes st ON st.theme_id = spt.theme_id
+							WHERE spt.post_id = p.post_id
+								AND (
+									st.theme_name = ANY($2)
+									OR st.theme_id IN (SELECT theme_id FROM theme_aliases WHERE alias = ANY($2))
+								)
+						)
+					)
+					AND ($3::INT = 0 OR p.user_id = $3)
+			),
+			page AS (
+				SELECT post_id, rank
+				FROM ranked
+				WHERE $4::REAL IS NULL OR (rank, post_id) < ($4, $5)
+				ORDER BY rank DESC, post_id DESC
+				LIMIT $6
+			)
+			SELECT p.post_id, p.user_id, p.login, p.header, p.content, p.created_at, p.visibility, p.version,
+				p.content_format, p.content_html, p.excerpt, p.word_count, p.reading_seconds,
+				p.comments_count,
+				COALESCE((SELECT ps.views FROM post_stats ps WHERE ps.post_id = p.post_id), 0),
+				COALESCE(
+					ARRAY_AGG(t.theme_name ORDER BY t.theme_name) FILTER (WHERE t.theme_name IS NOT NULL),
+					'{}'
+				),
+				pg.rank,
+				ts_headline('english', p.content, q.query, 'MaxFragments=2, MaxWords=30, MinWords=10')
+			FROM page pg
+			CROSS JOIN q
+			JOIN posts p ON p.post_id = pg.post_id
+			LEFT JOIN post_theme pt ON pt.post_id = p.post_id
+			LEFT JOIN themes t ON t.theme_id = pt.theme_id
+			GROUP BY p.post_id, pg.rank, q.query
+			ORDER BY pg.rank DESC, p.post_id DESC;`
+	)
+
+	afterRank, afterId := rankArgs(after)
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		slctQuery,
+		query,
+		pq.Array(themes),
+		userId,
+		afterRank,
+		afterId,
+		limit,
+		viewer.Id,
+		pq.Array(toInt64s(viewer.Following)),
+	)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	hits := make([]models.SearchHit, 0, limit)
+	for rows.Next() {
+		var (
+			hit            models.SearchHit
+			readingSeconds int
+		)
+
+		err = rows.Scan(
+			&hit.Post.Id,
+			&hit.Post.UserId,
+			&hit.Post.Login,
+			&hit.Post.Header,
+			&hit.Post.Content,
+			&hit.Post.CreatedAt,
+			&hit.Post.Visibility,
+			&hit.Post.Version,
+			&hit.Post.ContentFormat,
+			&hit.Post.Rendered.HTML,
+			&hit.Post.Rendered.Excerpt,
+			&hit.Post.Rendered.WordCount,
+			&readingSeconds,
+			&hit.Post.CommentsCount,
+			&hit.Post.Views,
+			pq.Array(&hit.Post.Themes),
+			&hit.Rank,
+			&hit.Snippet,
+		)
+		if err != nil {
+			return nil, fail(op, err)
+		}
+		hit.Post.Status = models.StatusPublished
+		hit.Post.Rendered.ReadingTime = time.Duration(readingSeconds) * time.Second
+
+		hits = append(hits, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return hits, nil
+}
+
+// rankArgs converts rank cursor to query arguments. Zero cursor is converted to NULL
+func rankArgs(r cursor.Rank) (sql.NullFloat64, int) {
+	if r.IsZero() {
+		return sql.NullFloat64{}, 0
+	}
+
+	return sql.NullFloat64{Float64: float64(r.Rank), Valid: true}, r.PostId
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+// AddViews adds views to stats of the posts in one statement. views maps post
+// id to number of new views. Views of missing posts are skipped, so posts
+// purged since the views were recorded do not fail the whole batch
+func (s *Storage) AddViews(
+	ctx context.Context,
+	views map[int]int,
+) error {
+	const (
+		op        = "postgres.AddViews"
+		upsrtStmt = `
+			INSERT INTO post_stats(post_id, views)
+			SELECT v.post_id, v.views
+			FROM UNNEST($1::INT[], $2::BIGINT[]) AS v(post_id, views)
+			JOIN posts p ON p.post_id = v.post_id
+			ON CONFLICT (post_id) DO UPDATE
+			SET views = post_stats.views + EXCLUDED.views, updated_at = NOW();`
+	)
+
+	if len(views) == 0 {
+		return nil
+	}
+
+	postIds := make([]int64, 0, len(views))
+	counts := make([]int64, 0, len(views))
+	for postId, n := range views {
+		postIds = append(postIds, int64(postId))
+		counts = append(counts, int64(n))
+	}
+
+	_, err := s.db.ExecContext(ctx, upsrtStmt, pq.Array(postIds), pq.Array(counts))
+	if err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
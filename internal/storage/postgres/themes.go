@@ -0,0 +1,391 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	"github.com/IlianBuh/Post-service/internal/storage"
+	"github.com/lib/pq"
+)
+
+// Themes returns at most limit themes ordered by number of posts. Only themes
+// placed after the cursor are returned
+func (s *Storage) Themes(
+	ctx context.Context,
+	after cursor.Count,
+	limit int,
+) ([]models.Theme, error) {
+	const (
+		op        = "postgres.Themes"
+		slctQuery = `
+			SELECT theme_id, theme_name, posts_count
+			FROM (
+				SELECT t.theme_id, t.theme_name, COUNT(pt.post_id) AS posts_count
+				FROM themes t
+				LEFT JOIN post_theme pt ON pt.theme_id = t.theme_id
+					AND pt.post_id IN (SELECT post_id FROM posts WHERE deleted_at IS NULL AND status = 'published' AND visibility = 'public')
+				GROUP BY t.theme_id
+			) c
+			WHERE $1::BIGINT IS NULL
+				OR c.posts_count < $1
+				OR (c.posts_count = $1 AND c.theme_id > $2)
+			ORDER BY c.posts_count DESC, c.theme_id
+			LIMIT $3;`
+	)
+
+	var afterCount sql.NullInt64
+	if !after.IsZero() {
+		afterCount = sql.NullInt64{Int64: int64(after.Count), Valid: true}
+	}
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, afterCount, after.Id, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	themes, err := scanThemes(rows, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return themes, nil
+}
+
+// ThemesByPrefix returns at most limit themes which names start with the
+// prefix ignoring case. The most used themes go first
+func (s *Storage) ThemesByPrefix(
+	ctx context.Context,
+	prefix string,
+	limit int,
+) ([]models.Theme, error) {
+	const (
+		op        = "postgres.ThemesByPrefix"
+		slctQuery = `
+			SELECT t.theme_id, t.theme_name, COUNT(pt.post_id) AS posts_count
+			FROM themes t
+			LEFT JOIN post_theme pt ON pt.theme_id = t.theme_id
+				AND pt.post_id IN (SELECT post_id FROM posts WHERE deleted_at IS NULL AND status = 'published' AND visibility = 'public')
+			WHERE LOWER(t.theme_name) LIKE LOWER($1) || '%'
+			GROUP BY t.theme_id
+			ORDER BY posts_count DESC, t.theme_name
+			LIMIT $2;`
+	)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, escapeLike(prefix), limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	themes, err := scanThemes(rows, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return themes, nil
+}
+
+// TrendingThemes returns at most limit themes ranked by ratio of posts made
+// within the window to the posts expected from the baseline. The baseline is
+// baselineWindows windows right before the current one. Themes without posts
+// within the window are skipped
+func (s *Storage) TrendingThemes(
+	ctx context.Context,
+	window time.Duration,
+	baselineWindows int,
+	limit int,
+) ([]models.TrendingTheme, error) {
+	const (
+		op = "postgres.TrendingThemes"
+		// expected number of posts is smoothed by one
+		// to keep the score finite for the new themes
+		slctQuery = `
+			WITH bounds AS (
+				SELECT NOW() - MAKE_INTERVAL(secs => $1::FLOAT8) AS window_start,
+					NOW() - MAKE_INTERVAL(secs => $1::FLOAT8 * (1 + $2::INT)) AS baseline_start
+			),
+			counts AS (
+				SELECT h.theme_id,
+					SUM(h.posts_count) FILTER (WHERE h.hour >= b.window_start) AS recent,
+					COALESCE(SUM(h.posts_count) FILTER (WHERE h.hour < b.window_start), 0) AS baseline
+				FROM theme_hourly_posts h, bounds b
+				WHERE h.hour >= b.baseline_start
+				GROUP BY h.theme_id
+			)
+			SELECT t.theme_id, t.theme_name, c.recent, c.baseline,
+				c.recent / (c.baseline::FLOAT8 / $2 + 1) AS score
+			FROM counts c
+			JOIN themes t ON t.theme_id = c.theme_id
+			WHERE c.recent > 0
+			ORDER BY score DESC, c.recent DESC, t.theme_id
+			LIMIT $3;`
+	)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, window.Seconds(), baselineWindows, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	themes := make([]models.TrendingTheme, 0, limit)
+	var theme models.TrendingTheme
+	for rows.Next() {
+		err = rows.Scan(
+			&theme.Theme.Id,
+			&theme.Theme.Name,
+			&theme.RecentPosts,
+			&theme.BaselinePosts,
+			&theme.Score,
+		)
+		if err != nil {
+			return nil, fail(op, err)
+		}
+
+		themes = append(themes, theme)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fail(op, err)
+	}
+
+	return themes, nil
+}
+
+// RefreshThemeStats recalculates aggregated statistics of themes usage
+func (s *Storage) RefreshThemeStats(ctx context.Context) error {
+	const (
+		op         = "postgres.RefreshThemeStats"
+		rfrshQuery = `
+			REFRESH MATERIALIZED VIEW CONCURRENTLY theme_hourly_posts;`
+	)
+
+	if _, err := s.db.ExecContext(ctx, rfrshQuery); err != nil {
+		return fail(op, err)
+	}
+
+	return nil
+}
+
+// MergeThemes moves all posts of source themes to the target theme and deletes
+// the sources. Names of the sources become aliases of the target. If the target
+// does not exist it is created. Sources are resolved through aliases, the ones
+// which resolve to the target are skipped. Returns [storage.ErrNotFound] if any
+// of the sources does not exist
+func (s *Storage) MergeThemes(
+	ctx context.Context,
+	sources []string,
+	target string,
+) error {
+	const (
+		op            = "postgres.MergeThemes"
+		rslvSrcsQuery = `
+			SELECT COALESCE(a.theme_id, t.theme_id)
+			FROM UNNEST($1::TEXT[]) AS s(name)
+				LEFT JOIN theme_aliases AS a ON a.alias = s.name
+				LEFT JOIN themes AS t ON t.theme_name = s.name;`
+		slctSrcsQuery = `
+			SELECT theme_id
+			FROM themes
+			WHERE theme_id = ANY($1)
+			FOR UPDATE;`
+		mvRelationsQuery = `
+			INSERT INTO post_theme(post_id, theme_id)
+			SELECT post_id, $1
+			FROM post_theme
+			WHERE theme_id = ANY($2)
+			ON CONFLICT DO NOTHING;`
+		mvAliasesQuery = `
+			UPDATE theme_aliases SET theme_id=$1 WHERE theme_id = ANY($2);`
+		insrtAliasesQuery = `
+			INSERT INTO theme_aliases(alias, theme_id)
+			SELECT theme_name, $1
+			FROM themes
+			WHERE theme_id = ANY($2)
+			ON CONFLICT (alias) DO UPDATE SET theme_id=EXCLUDED.theme_id;`
+		dltSrcsQuery = `
+			DELETE FROM themes WHERE theme_id = ANY($1);`
+	)
+	sendErr := func(err error) error {
+		return fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	thmIds, err := s.loadThemeIds(ctx, tx, []string{target})
+	if err != nil {
+		return sendErr(err)
+	}
+	targetId := thmIds[0]
+
+	rows, err := tx.QueryContext(ctx, rslvSrcsQuery, pq.Array(sources))
+	if err != nil {
+		return sendErr(err)
+	}
+	defer rows.Close()
+
+	var rslvdIds []int64
+	for rows.Next() {
+		var id sql.NullInt64
+		if err = rows.Scan(&id); err != nil {
+			return sendErr(err)
+		}
+		if !id.Valid {
+			return sendErr(storage.ErrNotFound)
+		}
+		if int(id.Int64) != targetId && !slices.Contains(rslvdIds, id.Int64) {
+			rslvdIds = append(rslvdIds, id.Int64)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return sendErr(err)
+	}
+	if len(rslvdIds) == 0 {
+		// the target can be created by the merge
+		if err = tx.Commit(); err != nil {
+			return sendErr(err)
+		}
+		return nil
+	}
+
+	// the source can be deleted by concurrent merge after it is resolved
+	rows, err = tx.QueryContext(ctx, slctSrcsQuery, pq.Array(rslvdIds))
+	if err != nil {
+		return sendErr(err)
+	}
+	srcIds, err := scanIds(rows)
+	if err != nil {
+		return sendErr(err)
+	}
+	if len(srcIds) != len(rslvdIds) {
+		return sendErr(storage.ErrNotFound)
+	}
+
+	// relations are deleted by cascade together with the sources
+	for _, query := range []string{mvRelationsQuery, mvAliasesQuery, insrtAliasesQuery} {
+		if _, err = tx.ExecContext(ctx, query, targetId, pq.Array(srcIds)); err != nil {
+			return sendErr(err)
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, dltSrcsQuery, pq.Array(srcIds)); err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// SaveAlias makes the alias a synonym of the theme. If the theme does not
+// exist it is created. Returns [storage.ErrThemeExists] if a theme named as
+// the alias exists, such themes must be merged instead
+func (s *Storage) SaveAlias(
+	ctx context.Context,
+	alias string,
+	theme string,
+) error {
+	const (
+		op             = "postgres.SaveAlias"
+		slctThemeQuery = `
+			SELECT EXISTS (SELECT 1 FROM themes WHERE theme_name=$1);`
+		insrtAliasQuery = `
+			INSERT INTO theme_aliases(alias, theme_id)
+			VALUES ($1, $2)
+			ON CONFLICT (alias) DO UPDATE SET theme_id=EXCLUDED.theme_id;`
+	)
+	sendErr := func(err error) error {
+		return fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err = tx.QueryRowContext(ctx, slctThemeQuery, alias).Scan(&exists); err != nil {
+		return sendErr(err)
+	}
+	if exists {
+		return sendErr(storage.ErrThemeExists)
+	}
+
+	thmIds, err := s.loadThemeIds(ctx, tx, []string{theme})
+	if err != nil {
+		return sendErr(err)
+	}
+
+	if _, err = tx.ExecContext(ctx, insrtAliasQuery, alias, thmIds[0]); err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// scanIds scans all rows with a single id column and closes them
+func scanIds(rows *sql.Rows) ([]int, error) {
+	defer rows.Close()
+
+	var (
+		ids []int
+		id  int
+	)
+	for rows.Next() {
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// scanThemes scans all rows with theme id, name and number of posts
+func scanThemes(rows *sql.Rows, capacity int) ([]models.Theme, error) {
+	themes := make([]models.Theme, 0, capacity)
+
+	var theme models.Theme
+	for rows.Next() {
+		if err := rows.Scan(&theme.Id, &theme.Name, &theme.PostsCount); err != nil {
+			return nil, err
+		}
+
+		themes = append(themes, theme)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return themes, nil
+}
+
+// likeEscaper escapes wildcards of LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the string to be used as a literal part of LIKE pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
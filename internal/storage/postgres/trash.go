@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/lib/cursor"
+	"github.com/IlianBuh/Post-service/internal/storage"
+)
+
+// RestorePost takes the post with the postId out of trash. Returns
+// [storage.ErrNotFound] if there is no such post in trash and
+// [storage.ErrNotCreator] if the user is not creator of the post
+func (s *Storage) RestorePost(
+	ctx context.Context,
+	postId int,
+	userId int,
+) error {
+	const (
+		op        = "postgres.RestorePost"
+		slctQuery = `
+			SELECT user_id
+			FROM posts
+			WHERE post_id = $1 AND deleted_at IS NOT NULL
+			FOR UPDATE;`
+		updtQuery = `
+			UPDATE posts SET deleted_at=NULL, version=version+1 WHERE post_id=$1;`
+	)
+	sendErr := func(err error) error {
+		return fail(op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sendErr(err)
+	}
+	defer tx.Rollback()
+
+	var recUserId int
+	err = tx.QueryRowContext(ctx, slctQuery, postId).Scan(&recUserId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sendErr(storage.ErrNotFound)
+		}
+
+		return sendErr(err)
+	}
+
+	if !s.isCreator(recUserId, userId) {
+		return sendErr(storage.ErrNotCreator)
+	}
+
+	if _, err = tx.ExecContext(ctx, updtQuery, postId); err != nil {
+		return sendErr(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return sendErr(err)
+	}
+
+	return nil
+}
+
+// Trash returns at most limit trashed posts of the user ordered from the most
+// recently deleted. Only posts placed after the cursor are returned, the cursor
+// is keyed on the deletion time
+func (s *Storage) Trash(
+	ctx context.Context,
+	userId int,
+	after cursor.Cursor,
+	limit int,
+) ([]models.Post, error) {
+	const (
+		op        = "postgres.Trash"
+		slctQuery = slctPostQuery + `
+			WHERE p.user_id = $1
+				AND p.deleted_at IS NOT NULL
+				AND ($2::TIMESTAMPTZ IS NULL OR (p.deleted_at, p.post_id) < ($2, $3))
+			GROUP BY p.post_id
+			ORDER BY p.deleted_at DESC, p.post_id DESC
+			LIMIT $4;`
+	)
+
+	afterTime, afterId := keysetArgs(after)
+
+	rows, err := s.db.QueryContext(ctx, slctQuery, userId, afterTime, afterId, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+	defer rows.Close()
+
+	posts, err := scanPosts(rows, limit)
+	if err != nil {
+		return nil, fail(op, err)
+	}
+
+	return posts, nil
+}
+
+// PurgeTrash permanently deletes at most limit posts trashed before the time.
+// Themes relations and revisions are deleted by cascade. Returns number of
+// deleted posts
+func (s *Storage) PurgeTrash(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) (int, error) {
+	const (
+		op       = "postgres.PurgeTrash"
+		dltQuery = `
+			DELETE FROM posts
+			WHERE post_id IN (
+				SELECT post_id
+				FROM posts
+				WHERE deleted_at < $1
+				ORDER BY deleted_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			);`
+	)
+
+	res, err := s.db.ExecContext(ctx, dltQuery, before, limit)
+	if err != nil {
+		return 0, fail(op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fail(op, err)
+	}
+
+	return int(n), nil
+}
@@ -5,8 +5,14 @@ import (
 )
 
 var (
-	ErrNotFound   = errors.New("not found")
-	ErrNotCreator = errors.New("user is not creator")
-	ErrClose      = errors.New("failed to close database")
-	ErrNoEvents   = errors.New("no new events")
+	ErrNotFound    = errors.New("not found")
+	ErrNotCreator  = errors.New("user is not creator")
+	ErrClose       = errors.New("failed to close database")
+	ErrNoEvents    = errors.New("no new events")
+	ErrThemeExists = errors.New("theme already exists")
+	ErrPublished   = errors.New("post is already published")
+	ErrConflict    = errors.New("version of the record does not match")
+	ErrKeyReused   = errors.New("idempotency key is used with another payload")
+	ErrFileExists  = errors.New("file already exists")
+	ErrNoParent    = errors.New("parent record does not exist")
 )
@@ -0,0 +1,183 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/bookmarks"
+	"github.com/IlianBuh/Post-service/internal/transport/validate"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AddBookmark makes request to service layer to save the post into the reading list of the viewer
+func (s *ServerAPI) AddBookmark(
+	ctx context.Context,
+	req *postv1.AddBookmarkRequest,
+) (*postv1.AddBookmarkResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.UserId(req.GetViewer().GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.ListName(req.GetList()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	err = s.bookmarks.Add(
+		ctx,
+		toViewer(req.GetViewer()),
+		req.GetList(),
+		int(req.GetPostId()),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, bookmarks.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found")
+		case errors.Is(err, bookmarks.ErrUserNotFound):
+			return nil, status.Error(codes.InvalidArgument, "user does not exist")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.AddBookmarkResponse{}, nil
+}
+
+// RemoveBookmark makes request to service layer to remove the post from the reading list of the user
+func (s *ServerAPI) RemoveBookmark(
+	ctx context.Context,
+	req *postv1.RemoveBookmarkRequest,
+) (*postv1.RemoveBookmarkResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.UserId(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.ListName(req.GetList()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	err = s.bookmarks.Remove(ctx, int(req.GetUserId()), req.GetList(), int(req.GetPostId()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.RemoveBookmarkResponse{}, nil
+}
+
+// ListBookmarks makes request to service layer to get page of the reading list of the viewer
+func (s *ServerAPI) ListBookmarks(
+	ctx context.Context,
+	req *postv1.ListBookmarksRequest,
+) (*postv1.ListBookmarksResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.UserId(req.GetViewer().GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.ListName(req.GetList()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, nextToken, err := s.bookmarks.List(
+		ctx,
+		toViewer(req.GetViewer()),
+		req.GetList(),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		if errors.Is(err, bookmarks.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.ListBookmarksResponse{
+		Bookmarks:     toBookmarkInfos(list),
+		NextPageToken: nextToken,
+	}, nil
+}
+
+// ListBookmarkLists makes request to service layer to get reading lists of the user
+func (s *ServerAPI) ListBookmarkLists(
+	ctx context.Context,
+	req *postv1.ListBookmarkListsRequest,
+) (*postv1.ListBookmarkListsResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.UserId(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	lists, err := s.bookmarks.Lists(ctx, int(req.GetUserId()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	res := make([]*postv1.BookmarkListInfo, 0, len(lists))
+	for _, l := range lists {
+		res = append(res, &postv1.BookmarkListInfo{
+			Name:  l.Name,
+			Count: int64(l.Count),
+		})
+	}
+
+	return &postv1.ListBookmarkListsResponse{Lists: res}, nil
+}
+
+// toBookmarkInfos converts bookmarks to their transport representation
+func toBookmarkInfos(list []models.Bookmark) []*postv1.BookmarkInfo {
+	res := make([]*postv1.BookmarkInfo, 0, len(list))
+	for _, b := range list {
+		res = append(res, &postv1.BookmarkInfo{
+			List:      b.List,
+			Post:      toPostInfo(b.Post),
+			CreatedAt: timestamppb.New(b.CreatedAt),
+		})
+	}
+
+	return res
+}
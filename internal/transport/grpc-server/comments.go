@@ -0,0 +1,219 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/comments"
+	"github.com/IlianBuh/Post-service/internal/transport/validate"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CreateComment makes request to service layer to comment the post or reply to the comment
+func (s *ServerAPI) CreateComment(
+	ctx context.Context,
+	req *postv1.CreateCommentRequest,
+) (*postv1.CreateCommentResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetParentId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Comment(req.GetContent()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	comment, err := s.comments.Create(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetPostId()),
+		int(req.GetParentId()),
+		req.GetContent(),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, comments.ErrUserNotFound):
+			return nil, status.Error(codes.InvalidArgument, "user does not exist")
+		case errors.Is(err, comments.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found")
+		case errors.Is(err, comments.ErrParentNotFound):
+			return nil, status.Error(codes.NotFound, "parent comment not found")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.CreateCommentResponse{Comment: toCommentInfo(comment)}, nil
+}
+
+// EditComment makes request to service layer to replace content of the comment
+func (s *ServerAPI) EditComment(
+	ctx context.Context,
+	req *postv1.EditCommentRequest,
+) (*postv1.EditCommentResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetCommentId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Comment(req.GetContent()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	comment, err := s.comments.Edit(
+		ctx,
+		int(req.GetCommentId()),
+		int(req.GetUserId()),
+		req.GetContent(),
+	)
+	if err != nil {
+		return nil, commentErr(err)
+	}
+
+	return &postv1.EditCommentResponse{Comment: toCommentInfo(comment)}, nil
+}
+
+// DeleteComment makes request to service layer to delete the comment
+func (s *ServerAPI) DeleteComment(
+	ctx context.Context,
+	req *postv1.DeleteCommentRequest,
+) (*postv1.DeleteCommentResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetCommentId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	if err = s.comments.Delete(ctx, int(req.GetCommentId()), int(req.GetUserId())); err != nil {
+		return nil, commentErr(err)
+	}
+
+	return &postv1.DeleteCommentResponse{}, nil
+}
+
+// ListComments makes request to service layer to get page of comments of the post
+func (s *ServerAPI) ListComments(
+	ctx context.Context,
+	req *postv1.ListCommentsRequest,
+) (*postv1.ListCommentsResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetParentId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, nextToken, err := s.comments.List(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetPostId()),
+		int(req.GetParentId()),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, comments.ErrInvalidToken):
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		case errors.Is(err, comments.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.ListCommentsResponse{
+		Comments:      toCommentInfos(list),
+		NextPageToken: nextToken,
+	}, nil
+}
+
+// commentErr converts error of editing or deleting the comment to the status
+func commentErr(err error) error {
+	switch {
+	case errors.Is(err, comments.ErrNotFound):
+		return status.Error(codes.NotFound, "comment not found")
+	case errors.Is(err, comments.ErrNotCreator):
+		return status.Error(codes.PermissionDenied, "user is not creator")
+	}
+
+	return status.Error(codes.Internal, codes.Internal.String())
+}
+
+// toCommentInfos converts comments to their transport representation
+func toCommentInfos(list []models.Comment) []*postv1.CommentInfo {
+	res := make([]*postv1.CommentInfo, 0, len(list))
+	for _, comment := range list {
+		res = append(res, toCommentInfo(comment))
+	}
+
+	return res
+}
+
+// toCommentInfo converts comment model to its' transport representation
+func toCommentInfo(comment models.Comment) *postv1.CommentInfo {
+	info := &postv1.CommentInfo{
+		CommentId:    int64(comment.Id),
+		PostId:       int64(comment.PostId),
+		ParentId:     int64(comment.ParentId),
+		UserId:       int64(comment.UserId),
+		Content:      comment.Content,
+		CreatedAt:    timestamppb.New(comment.CreatedAt),
+		RepliesCount: int64(comment.RepliesCount),
+		Replies:      toCommentInfos(comment.Replies),
+	}
+	if !comment.UpdatedAt.IsZero() {
+		info.UpdatedAt = timestamppb.New(comment.UpdatedAt)
+	}
+	if !comment.DeletedAt.IsZero() {
+		info.DeletedAt = timestamppb.New(comment.DeletedAt)
+	}
+
+	return info
+}
@@ -0,0 +1,43 @@
+package grpcserver
+
+import (
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// contentFormats maps protobuf content formats to the domain ones.
+// Unspecified format is left to the service layer
+var contentFormats = map[postv1.ContentFormat]models.ContentFormat{
+	postv1.ContentFormat_CONTENT_FORMAT_UNSPECIFIED: "",
+	postv1.ContentFormat_CONTENT_FORMAT_PLAIN:       models.FormatPlain,
+	postv1.ContentFormat_CONTENT_FORMAT_MARKDOWN:    models.FormatMarkdown,
+}
+
+// fromContentFormat converts protobuf content format to the domain one.
+// Reports false if the format is unknown
+func fromContentFormat(f postv1.ContentFormat) (models.ContentFormat, bool) {
+	res, ok := contentFormats[f]
+	return res, ok
+}
+
+// toContentFormat converts domain content format to the protobuf one
+func toContentFormat(f models.ContentFormat) postv1.ContentFormat {
+	for pbFormat, format := range contentFormats {
+		if format == f && format != "" {
+			return pbFormat
+		}
+	}
+
+	return postv1.ContentFormat_CONTENT_FORMAT_UNSPECIFIED
+}
+
+// toRenderedContent converts rendered content to its' transport representation
+func toRenderedContent(r models.RenderedContent) *postv1.RenderedContent {
+	return &postv1.RenderedContent{
+		Html:        r.HTML,
+		Excerpt:     r.Excerpt,
+		WordCount:   int32(r.WordCount),
+		ReadingTime: durationpb.New(r.ReadingTime),
+	}
+}
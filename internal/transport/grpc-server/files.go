@@ -0,0 +1,253 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/attachments"
+	"github.com/IlianBuh/Post-service/internal/transport/validate"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// downloadChunkSize is the maximum size of file data in one download message
+const downloadChunkSize = 64 << 10
+
+// UploadFile makes request to service layer to attach the streamed file to the post.
+// Post, user and file name are taken from the first message of the stream
+func (s *ServerAPI) UploadFile(
+	stream grpc.ClientStreamingServer[postv1.UploadFileRequest, postv1.UploadFileResponse],
+) error {
+	ctx := stream.Context()
+
+	var err error
+	if err = ctx.Err(); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	req, err := stream.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.InvalidArgument, "empty upload stream")
+		}
+		return err
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.FileName(req.GetFileName()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	att, err := s.attachments.Upload(
+		ctx,
+		int(req.GetPostId()),
+		int(req.GetUserId()),
+		req.GetFileName(),
+		&uploadReader{stream: stream, buf: req.GetFileData()},
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, attachments.ErrNotFound):
+			return status.Error(codes.NotFound, "post not found")
+		case errors.Is(err, attachments.ErrNotCreator):
+			return status.Error(codes.PermissionDenied, "user is not creator")
+		case errors.Is(err, attachments.ErrFileExists):
+			return status.Error(codes.AlreadyExists, "file already exists")
+		case errors.Is(err, attachments.ErrFileTooLarge):
+			return status.Error(codes.ResourceExhausted, "file is too large")
+		}
+		return status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return stream.SendAndClose(&postv1.UploadFileResponse{
+		Attachment: toAttachmentInfo(att),
+	})
+}
+
+// DownloadFile makes request to service layer to get the file attached to the post
+// and streams it by chunks. Metadata of the file is sent in the first message
+func (s *ServerAPI) DownloadFile(
+	req *postv1.DownloadFileRequest,
+	stream grpc.ServerStreamingServer[postv1.DownloadFileResponse],
+) error {
+	ctx := stream.Context()
+
+	var err error
+	if err = ctx.Err(); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.FileName(req.GetFileName()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	att, content, err := s.attachments.Download(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetPostId()),
+		req.GetFileName(),
+		req.GetVariant(),
+	)
+	if err != nil {
+		if errors.Is(err, attachments.ErrNotFound) {
+			return status.Error(codes.NotFound, "file not found")
+		}
+		return status.Error(codes.Internal, codes.Internal.String())
+	}
+	defer content.Close()
+
+	resp := &postv1.DownloadFileResponse{Attachment: toAttachmentInfo(att)}
+	buf := make([]byte, downloadChunkSize)
+	for {
+		n, err := io.ReadFull(content, buf)
+		if n > 0 || resp.Attachment != nil {
+			resp.Chunks = buf[:n]
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			resp = &postv1.DownloadFileResponse{}
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return status.Error(codes.Internal, codes.Internal.String())
+		}
+	}
+}
+
+// GetAttachment makes request to service layer to get metadata of the file
+// attached to the post, including processing status and variants of the image
+func (s *ServerAPI) GetAttachment(
+	ctx context.Context,
+	req *postv1.GetAttachmentRequest,
+) (*postv1.GetAttachmentResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.FileName(req.GetFileName()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	att, err := s.attachments.Get(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetPostId()),
+		req.GetFileName(),
+	)
+	if err != nil {
+		if errors.Is(err, attachments.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "file not found")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.GetAttachmentResponse{Attachment: toAttachmentInfo(att)}, nil
+}
+
+// uploadReader reads file data from the upload stream. Data
+// of the first message is passed in buf, as it's already received
+type uploadReader struct {
+	stream grpc.ClientStreamingServer[postv1.UploadFileRequest, postv1.UploadFileResponse]
+	buf    []byte
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+
+		r.buf = req.GetFileData()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+// toAttachmentInfo converts attachment model to its' transport representation
+func toAttachmentInfo(att models.Attachment) *postv1.AttachmentInfo {
+	return &postv1.AttachmentInfo{
+		PostId:      int64(att.PostId),
+		FileName:    att.FileName,
+		Size:        att.Size,
+		MimeType:    att.MimeType,
+		Sha256:      att.Sha256,
+		CreatedAt:   timestamppb.New(att.CreatedAt),
+		ImageStatus: toImageStatus(att.ImageStatus),
+		Width:       int32(att.Width),
+		Height:      int32(att.Height),
+		Variants:    toImageVariantInfos(att.Variants),
+	}
+}
+
+// imageStatuses maps domain image statuses to the protobuf ones,
+// attachment which is not an image has unspecified status
+var imageStatuses = map[models.ImageStatus]postv1.ImageStatus{
+	models.ImagePending: postv1.ImageStatus_IMAGE_STATUS_PENDING,
+	models.ImageReady:   postv1.ImageStatus_IMAGE_STATUS_READY,
+	models.ImageFailed:  postv1.ImageStatus_IMAGE_STATUS_FAILED,
+}
+
+// toImageStatus converts domain image status to the protobuf one
+func toImageStatus(st models.ImageStatus) postv1.ImageStatus {
+	if res, ok := imageStatuses[st]; ok {
+		return res
+	}
+
+	return postv1.ImageStatus_IMAGE_STATUS_UNSPECIFIED
+}
+
+// toImageVariantInfos converts image variants to their transport
+// representation. Blob keys are internal and not exposed
+func toImageVariantInfos(variants []models.ImageVariant) []*postv1.ImageVariantInfo {
+	res := make([]*postv1.ImageVariantInfo, 0, len(variants))
+	for _, v := range variants {
+		res = append(res, &postv1.ImageVariantInfo{
+			Name:     v.Name,
+			Width:    int32(v.Width),
+			Height:   int32(v.Height),
+			Size:     v.Size,
+			MimeType: v.MimeType,
+		})
+	}
+
+	return res
+}
@@ -3,59 +3,437 @@ package grpcserver
 import (
 	"context"
 	"errors"
+	"io"
 
 	"time"
 
+	"github.com/IlianBuh/Post-service/internal/domain/models"
 	"github.com/IlianBuh/Post-service/internal/service/posts"
+	"github.com/IlianBuh/Post-service/internal/service/themes"
 	"github.com/IlianBuh/Post-service/internal/transport/validate"
 	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type PostService interface {
 
-	// Create creates new post
+	// Create creates new post. Repeated request with the same
+	// idempotency key returns id of the already created post.
+	// Return values: post id, themes inferred from hashtags, error
 	Create(
 		ctx context.Context,
 		userId int,
 		login string,
 		header string,
 		content string,
+		format models.ContentFormat,
 		themes []string,
-	) (int, error)
+		inferThemes bool,
+		status models.PostStatus,
+		publishAt time.Time,
+		visibility models.Visibility,
+		idempotencyKey string,
+	) (int, []string, error)
 
-	// Update updates all post fields with postId.
-	// If the value is the default value (zero value), the old value will be saved.
-	// User id is used to verify if  the user is a creator
+	// Update updates post fields named in the mask, addThemes and
+	// removeThemes are applied after that. If the mask is zero, fields
+	// with the default value (zero value) keep the old values.
+	// User id is used to verify if  the user is a creator.
+	// Return values: themes inferred from hashtags, error
 	Update(
 		ctx context.Context,
 		userId int,
 		postId int,
 		header string,
 		content string,
+		format models.ContentFormat,
 		themes []string,
+		mask models.UpdateMask,
+		addThemes []string,
+		removeThemes []string,
+		inferThemes bool,
+		version int,
+	) ([]string, error)
+
+	// Delete moves the post to trash.
+	// User id is used to verify if  the user is a creator.
+	// Zero version skips the check of the post version
+	Delete(
+		ctx context.Context,
+		postId int,
+		userId int,
+		version int,
 	) error
 
-	// Delete deletes all post related entries..
+	// Get returns post with postId and all its themes if the viewer can read it
+	Get(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+	) (models.Post, error)
+
+	// GetMany returns posts with postIds in the order of the ids and
+	// ids of the posts that do not exist or are hidden from the viewer
+	GetMany(
+		ctx context.Context,
+		viewer models.Viewer,
+		postIds []int,
+	) ([]models.Post, []int, error)
+
+	// ListByUser returns page of users' posts, newest first, and token of the next page.
+	// Empty token means the first page for request and the last page for response
+	ListByUser(
+		ctx context.Context,
+		viewer models.Viewer,
+		userId int,
+		pageToken string,
+		pageSize int,
+	) ([]models.Post, string, error)
+
+	// ListByThemes returns page of posts, newest first, that match themes, and token
+	// of the next page. If matchAll is set, post must have all themes, otherwise any
+	// of them. Zero userId means posts of any author
+	ListByThemes(
+		ctx context.Context,
+		viewer models.Viewer,
+		themes []string,
+		matchAll bool,
+		userId int,
+		pageToken string,
+		pageSize int,
+	) ([]models.Post, string, error)
+
+	// Feed returns page of posts of the authors merged into one timeline, newest first,
+	// and token of the next page. Non-empty themes require posts to have any of them,
+	// posts with excluded themes or ids are skipped
+	Feed(
+		ctx context.Context,
+		viewer models.Viewer,
+		authorIds []int,
+		themes []string,
+		excludeThemes []string,
+		excludePostIds []int,
+		pageToken string,
+		pageSize int,
+	) ([]models.Post, string, error)
+
+	// ListRevisions returns page of post revisions, the newest first, and token of the next page
+	ListRevisions(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+		pageToken string,
+		pageSize int,
+	) ([]models.Revision, string, error)
+
+	// GetRevision returns the revision of the post
+	GetRevision(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+		revision int,
+	) (models.Revision, error)
+
+	// DiffRevisions returns line-based diff between two revisions of the post.
+	// Zero revision means the current state of the post
+	DiffRevisions(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+		from int,
+		to int,
+	) (models.RevisionDiff, error)
+
+	// RestoreRevision makes the revision the current state of the post.
+	// User id is used to verify if  the user is a creator
+	RestoreRevision(
+		ctx context.Context,
+		userId int,
+		postId int,
+		revision int,
+	) error
+
+	// Search returns page of posts matching full-text query, the most relevant first,
+	// and token of the next page. Empty themes and zero userId disable the filters
+	Search(
+		ctx context.Context,
+		viewer models.Viewer,
+		query string,
+		themes []string,
+		userId int,
+		pageToken string,
+		pageSize int,
+	) ([]models.SearchHit, string, error)
+
+	// RestorePost takes the deleted post out of trash.
 	// User id is used to verify if  the user is a creator
+	RestorePost(
+		ctx context.Context,
+		postId int,
+		userId int,
+	) error
+
+	// ListTrash returns page of users' deleted posts, the most recently
+	// deleted first, and token of the next page
+	ListTrash(
+		ctx context.Context,
+		userId int,
+		pageToken string,
+		pageSize int,
+	) ([]models.Post, string, error)
+
+	// Publish publishes the unpublished post at publishAt, right now if it is
+	// zero or not in the future. User id is used to verify if  the user is a creator
+	Publish(
+		ctx context.Context,
+		postId int,
+		userId int,
+		publishAt time.Time,
+	) error
+
+	// ListUnpublished returns page of users' drafts and scheduled posts,
+	// newest first, and token of the next page
+	ListUnpublished(
+		ctx context.Context,
+		userId int,
+		pageToken string,
+		pageSize int,
+	) ([]models.Post, string, error)
+}
+
+type ThemeService interface {
+
+	// List returns page of themes, the most used first, and token of the next page.
+	// Empty token means the first page for request and the last page for response
+	List(
+		ctx context.Context,
+		pageToken string,
+		pageSize int,
+	) ([]models.Theme, string, error)
+
+	// Suggest returns themes which names start with the prefix, the most used first
+	Suggest(
+		ctx context.Context,
+		prefix string,
+		limit int,
+	) ([]models.Theme, error)
+
+	// Trending returns themes which got more posts within the window than usually,
+	// the most trending first. Zero window means the default one
+	Trending(
+		ctx context.Context,
+		window time.Duration,
+		limit int,
+	) ([]models.TrendingTheme, error)
+
+	// Merge moves posts of source themes to the target one and makes sources
+	// its' aliases. User id is used to verify if the user is an admin
+	Merge(
+		ctx context.Context,
+		userId int,
+		sources []string,
+		target string,
+	) error
+
+	// AddAlias makes alias a synonym of the theme.
+	// User id is used to verify if the user is an admin
+	AddAlias(
+		ctx context.Context,
+		userId int,
+		alias string,
+		theme string,
+	) error
+}
+
+type AttachmentService interface {
+
+	// Upload reads the file from r and attaches it to the post.
+	// User id is used to verify if the user is a creator
+	Upload(
+		ctx context.Context,
+		postId int,
+		userId int,
+		fileName string,
+		r io.Reader,
+	) (models.Attachment, error)
+
+	// Get returns metadata of the file attached to the post
+	// if the viewer can read the post
+	Get(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+		fileName string,
+	) (models.Attachment, error)
+
+	// Download returns the file or its' variant attached to the post if the
+	// viewer can read the post. Returned reader must be closed
+	Download(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+		fileName string,
+		variant string,
+	) (models.Attachment, io.ReadCloser, error)
+}
+
+type ReactionService interface {
+
+	// React sets reaction of the viewer to the post if the viewer can read
+	// it. Return values: counters of reactions to the post, error
+	React(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+		kind string,
+	) ([]models.ReactionCount, error)
+
+	// Unreact removes reaction of the user to the post.
+	// Return values: counters of reactions to the post, error
+	Unreact(
+		ctx context.Context,
+		userId int,
+		postId int,
+	) ([]models.ReactionCount, error)
+
+	// ListReactors returns page of reactions to the post if the viewer can
+	// read it. Return values: reactions, counters, next page token, error
+	ListReactors(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+		kind string,
+		pageToken string,
+		pageSize int,
+	) ([]models.Reaction, []models.ReactionCount, string, error)
+}
+
+type CommentService interface {
+
+	// Create comments the post if the viewer can read it, non-zero parentId
+	// makes the comment a reply. Return values: saved comment, error
+	Create(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+		parentId int,
+		content string,
+	) (models.Comment, error)
+
+	// Edit replaces content of the comment. User id is used to verify if
+	// the user is a creator. Return values: edited comment, error
+	Edit(
+		ctx context.Context,
+		commentId int,
+		userId int,
+		content string,
+	) (models.Comment, error)
+
+	// Delete deletes the comment. User id is used
+	// to verify if the user is a creator
 	Delete(
 		ctx context.Context,
+		commentId int,
+		userId int,
+	) error
+
+	// List returns page of comments to the post or replies to the parent
+	// comment. Return values: comments with first replies, next page token, error
+	List(
+		ctx context.Context,
+		viewer models.Viewer,
+		postId int,
+		parentId int,
+		pageToken string,
+		pageSize int,
+	) ([]models.Comment, string, error)
+}
+
+type BookmarkService interface {
+
+	// Add saves the post into the reading list of the viewer
+	// if the viewer can read the post
+	Add(
+		ctx context.Context,
+		viewer models.Viewer,
+		list string,
 		postId int,
+	) error
+
+	// Remove removes the post from the reading list of the user
+	Remove(
+		ctx context.Context,
+		userId int,
+		list string,
+		postId int,
+	) error
+
+	// List returns page of the reading list of the viewer.
+	// Return values: bookmarks, next page token, error
+	List(
+		ctx context.Context,
+		viewer models.Viewer,
+		list string,
+		pageToken string,
+		pageSize int,
+	) ([]models.Bookmark, string, error)
+
+	// Lists returns reading lists of the user.
+	// Return values: lists, error
+	Lists(
+		ctx context.Context,
 		userId int,
+	) ([]models.BookmarkList, error)
+}
+
+type ViewService interface {
+
+	// RecordView counts the view of the post by the viewer.
+	// Counts are saved in the background
+	RecordView(
+		ctx context.Context,
+		postId int,
+		viewerId int,
 	) error
 }
 
 type ServerAPI struct {
 	postv1.UnimplementedPostServer
-	srvc    PostService
-	timeout time.Duration
+	srvc        PostService
+	themes      ThemeService
+	attachments AttachmentService
+	reactions   ReactionService
+	comments    CommentService
+	bookmarks   BookmarkService
+	views       ViewService
+	timeout     time.Duration
 }
 
 // Register registers serverAPI on srv grpc-server
-func Register(srv grpc.ServiceRegistrar, post PostService, timeout time.Duration) {
-	postv1.RegisterPostServer(srv, &ServerAPI{srvc: post, timeout: timeout})
+func Register(
+	srv grpc.ServiceRegistrar,
+	post PostService,
+	theme ThemeService,
+	attachment AttachmentService,
+	reaction ReactionService,
+	comment CommentService,
+	bookmark BookmarkService,
+	view ViewService,
+	timeout time.Duration,
+) {
+	postv1.RegisterPostServer(srv, &ServerAPI{
+		srvc:        post,
+		themes:      theme,
+		attachments: attachment,
+		reactions:   reaction,
+		comments:    comment,
+		bookmarks:   bookmark,
+		views:       view,
+		timeout:     timeout,
+	})
 }
 
 // Create makes request to service layer to create a new post
@@ -71,26 +449,55 @@ func (s *ServerAPI) Create(ctx context.Context, req *postv1.CreateRequest) (*pos
 	if err = validate.Header(req.GetHeader()); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	postStatus, ok := fromPostStatus(req.GetStatus())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown post status")
+	}
+	visibility, ok := fromVisibility(req.GetVisibility())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown visibility")
+	}
+	format, ok := fromContentFormat(req.GetContentFormat())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown content format")
+	}
+	if err = validate.IdempotencyKey(req.GetIdempotencyKey()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	postId, err := s.srvc.Create(
+	postId, inferred, err := s.srvc.Create(
 		ctx,
 		int(req.GetUserId()),
 		req.GetLogin(),
 		req.GetHeader(),
 		req.GetContent(),
+		format,
 		req.GetThemes(),
+		req.GetInferThemes(),
+		postStatus,
+		toTime(req.GetPublishAt()),
+		visibility,
+		req.GetIdempotencyKey(),
 	)
 	if err != nil {
-		if errors.Is(err, posts.ErrUserNotFound) {
+		switch {
+		case errors.Is(err, posts.ErrUserNotFound):
 			return nil, status.Error(codes.InvalidArgument, "user does not exist")
+		case errors.Is(err, posts.ErrInvalidTime):
+			return nil, status.Error(codes.InvalidArgument, "publishing time must be in the future")
+		case errors.Is(err, posts.ErrKeyReused):
+			return nil, status.Error(codes.FailedPrecondition, "idempotency key is reused with another payload")
 		}
 		return nil, status.Error(codes.Internal, codes.Internal.String())
 	}
 
-	return &postv1.CreateResponse{PostId: int64(postId)}, nil
+	return &postv1.CreateResponse{
+		PostId:         int64(postId),
+		InferredThemes: inferred,
+	}, nil
 }
 
 // Update makes request to service layer to change the existing post
@@ -106,28 +513,53 @@ func (s *ServerAPI) Update(ctx context.Context, req *postv1.UpdateRequest) (*pos
 	if err = validate.Id(req.GetPostId()); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
-	if err = validate.Header(req.GetHeader()); err != nil {
+	mask, err := fromUpdateMask(req.GetUpdateMask())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	format, ok := fromContentFormat(req.GetContentFormat())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown content format")
+	}
+	if mask.IsZero() || mask.Header {
+		if err = validate.Header(req.GetHeader()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if err = validate.Version(req.GetVersion()); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	ctx, cnl := context.WithTimeout(ctx, s.timeout)
 	defer cnl()
 
-	err = s.srvc.Update(
+	inferred, err := s.srvc.Update(
 		ctx,
 		int(req.GetUserId()),
 		int(req.GetPostId()),
 		req.GetHeader(),
 		req.GetContent(),
+		format,
 		req.GetThemes(),
+		mask,
+		req.GetAddThemes(),
+		req.GetRemoveThemes(),
+		req.GetInferThemes(),
+		int(req.GetVersion()),
 	)
 	if err != nil {
-		// TODO : handle some errors
-
-		return nil, status.Error(codes.Internal, "Intenal")
+		switch {
+		case errors.Is(err, posts.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found")
+		case errors.Is(err, posts.ErrNotCreator):
+			return nil, status.Error(codes.PermissionDenied, "user is not creator")
+		case errors.Is(err, posts.ErrConflict):
+			return nil, status.Error(codes.Aborted, "post was changed concurrently")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
 	}
 
-	return &postv1.UpdateResponse{}, nil
+	return &postv1.UpdateResponse{InferredThemes: inferred}, nil
 }
 
 // Delete makes request to service layer to delete the existing post
@@ -143,16 +575,522 @@ func (s *ServerAPI) Delete(ctx context.Context, req *postv1.DeleteRequest) (*pos
 	if err = validate.Id(req.GetUserId()); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	if err = validate.Version(req.GetVersion()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	ctx, cnl := context.WithTimeout(ctx, s.timeout)
 	defer cnl()
 
-	err = s.srvc.Delete(ctx, int(req.GetPostId()), int(req.GetUserId()))
+	err = s.srvc.Delete(
+		ctx,
+		int(req.GetPostId()),
+		int(req.GetUserId()),
+		int(req.GetVersion()),
+	)
 	if err != nil {
-		// TODO : handle some errors
-
-		return nil, status.Error(codes.Internal, "Inernal")
+		switch {
+		case errors.Is(err, posts.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found")
+		case errors.Is(err, posts.ErrNotCreator):
+			return nil, status.Error(codes.PermissionDenied, "user is not creator")
+		case errors.Is(err, posts.ErrConflict):
+			return nil, status.Error(codes.Aborted, "post was changed concurrently")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
 	}
 
 	return &postv1.DeleteResponse{}, nil
 }
+
+// Get makes request to service layer to get the existing post
+func (s *ServerAPI) Get(ctx context.Context, req *postv1.GetRequest) (*postv1.GetResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	post, err := s.srvc.Get(ctx, toViewer(req.GetViewer()), int(req.GetPostId()))
+	if err != nil {
+		if errors.Is(err, posts.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "post not found")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.GetResponse{Post: toPostInfo(post)}, nil
+}
+
+// GetPosts makes request to service layer to get several posts at once
+func (s *ServerAPI) GetPosts(ctx context.Context, req *postv1.GetPostsRequest) (*postv1.GetPostsResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Ids(req.GetPostIds()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, missing, err := s.srvc.GetMany(ctx, toViewer(req.GetViewer()), toInts(req.GetPostIds()))
+	if err != nil {
+		if errors.Is(err, posts.ErrTooManyIds) {
+			return nil, status.Error(codes.InvalidArgument, "too many ids")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	missingIds := make([]int64, len(missing))
+	for i, id := range missing {
+		missingIds[i] = int64(id)
+	}
+
+	return &postv1.GetPostsResponse{
+		Posts:      toPostInfos(list),
+		MissingIds: missingIds,
+	}, nil
+}
+
+// ListPostsByUser makes request to service layer to get page of users' posts
+func (s *ServerAPI) ListPostsByUser(
+	ctx context.Context,
+	req *postv1.ListPostsByUserRequest,
+) (*postv1.ListPostsByUserResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, nextToken, err := s.srvc.ListByUser(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetUserId()),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		if errors.Is(err, posts.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.ListPostsByUserResponse{
+		Posts:         toPostInfos(list),
+		NextPageToken: nextToken,
+	}, nil
+}
+
+// ListPostsByThemes makes request to service layer to get page of posts with the themes
+func (s *ServerAPI) ListPostsByThemes(
+	ctx context.Context,
+	req *postv1.ListPostsByThemesRequest,
+) (*postv1.ListPostsByThemesResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Themes(req.GetThemes()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, nextToken, err := s.srvc.ListByThemes(
+		ctx,
+		toViewer(req.GetViewer()),
+		req.GetThemes(),
+		req.GetMatchAll(),
+		int(req.GetUserId()),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		if errors.Is(err, posts.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.ListPostsByThemesResponse{
+		Posts:         toPostInfos(list),
+		NextPageToken: nextToken,
+	}, nil
+}
+
+// Feed makes request to service layer to get timeline of the authors
+func (s *ServerAPI) Feed(ctx context.Context, req *postv1.FeedRequest) (*postv1.FeedResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Ids(req.GetAuthorIds()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, nextToken, err := s.srvc.Feed(
+		ctx,
+		toViewer(req.GetViewer()),
+		toInts(req.GetAuthorIds()),
+		req.GetThemes(),
+		req.GetExcludeThemes(),
+		toInts(req.GetExcludePostIds()),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, posts.ErrInvalidToken):
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		case errors.Is(err, posts.ErrTooManyIds):
+			return nil, status.Error(codes.InvalidArgument, "too many ids")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.FeedResponse{
+		Posts:         toPostInfos(list),
+		NextPageToken: nextToken,
+	}, nil
+}
+
+// Search makes request to service layer to find posts by full-text query
+func (s *ServerAPI) Search(ctx context.Context, req *postv1.SearchRequest) (*postv1.SearchResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Query(req.GetQuery()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	hits, nextToken, err := s.srvc.Search(
+		ctx,
+		toViewer(req.GetViewer()),
+		req.GetQuery(),
+		req.GetThemes(),
+		int(req.GetUserId()),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		if errors.Is(err, posts.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	res := make([]*postv1.SearchHit, len(hits))
+	for i, hit := range hits {
+		res[i] = &postv1.SearchHit{
+			Post:    toPostInfo(hit.Post),
+			Rank:    hit.Rank,
+			Snippet: hit.Snippet,
+		}
+	}
+
+	return &postv1.SearchResponse{Hits: res, NextPageToken: nextToken}, nil
+}
+
+// ListThemes makes request to service layer to get page of themes
+func (s *ServerAPI) ListThemes(
+	ctx context.Context,
+	req *postv1.ListThemesRequest,
+) (*postv1.ListThemesResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, nextToken, err := s.themes.List(ctx, req.GetPageToken(), int(req.GetPageSize()))
+	if err != nil {
+		if errors.Is(err, themes.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.ListThemesResponse{
+		Themes:        toThemeInfos(list),
+		NextPageToken: nextToken,
+	}, nil
+}
+
+// SuggestThemes makes request to service layer to get themes starting with the prefix
+func (s *ServerAPI) SuggestThemes(
+	ctx context.Context,
+	req *postv1.SuggestThemesRequest,
+) (*postv1.SuggestThemesResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Prefix(req.GetPrefix()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetLimit()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, err := s.themes.Suggest(ctx, req.GetPrefix(), int(req.GetLimit()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.SuggestThemesResponse{Themes: toThemeInfos(list)}, nil
+}
+
+// TrendingThemes makes request to service layer to get the most trending themes
+func (s *ServerAPI) TrendingThemes(
+	ctx context.Context,
+	req *postv1.TrendingThemesRequest,
+) (*postv1.TrendingThemesResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.PageSize(req.GetLimit()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, err := s.themes.Trending(ctx, req.GetWindow().AsDuration(), int(req.GetLimit()))
+	if err != nil {
+		if errors.Is(err, themes.ErrInvalidWindow) {
+			return nil, status.Error(codes.InvalidArgument, "window must be from 1 to 72 hours")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	res := make([]*postv1.TrendingThemeInfo, len(list))
+	for i, theme := range list {
+		res[i] = &postv1.TrendingThemeInfo{
+			ThemeId:       int64(theme.Theme.Id),
+			Name:          theme.Theme.Name,
+			RecentPosts:   int64(theme.RecentPosts),
+			BaselinePosts: int64(theme.BaselinePosts),
+			Score:         theme.Score,
+		}
+	}
+
+	return &postv1.TrendingThemesResponse{Themes: res}, nil
+}
+
+// MergeThemes makes request to service layer to merge source themes into the target one
+func (s *ServerAPI) MergeThemes(
+	ctx context.Context,
+	req *postv1.MergeThemesRequest,
+) (*postv1.MergeThemesResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Themes(req.GetSources()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Theme(req.GetTarget()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	err = s.themes.Merge(ctx, int(req.GetUserId()), req.GetSources(), req.GetTarget())
+	if err != nil {
+		return nil, themeStatus(err)
+	}
+
+	return &postv1.MergeThemesResponse{}, nil
+}
+
+// AddThemeAlias makes request to service layer to add synonym of the theme
+func (s *ServerAPI) AddThemeAlias(
+	ctx context.Context,
+	req *postv1.AddThemeAliasRequest,
+) (*postv1.AddThemeAliasResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Theme(req.GetAlias()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Theme(req.GetTheme()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	err = s.themes.AddAlias(ctx, int(req.GetUserId()), req.GetAlias(), req.GetTheme())
+	if err != nil {
+		return nil, themeStatus(err)
+	}
+
+	return &postv1.AddThemeAliasResponse{}, nil
+}
+
+// themeStatus converts error of theme administration to grpc status
+func themeStatus(err error) error {
+	switch {
+	case errors.Is(err, themes.ErrForbidden):
+		return status.Error(codes.PermissionDenied, "user is not admin")
+	case errors.Is(err, themes.ErrInvalidTheme):
+		return status.Error(codes.InvalidArgument, "invalid theme")
+	case errors.Is(err, themes.ErrNotFound):
+		return status.Error(codes.NotFound, "theme not found")
+	case errors.Is(err, themes.ErrThemeExists):
+		return status.Error(codes.AlreadyExists, "theme already exists, merge it instead")
+	}
+
+	return status.Error(codes.Internal, codes.Internal.String())
+}
+
+// toThemeInfos converts list of theme models to its' transport representation
+func toThemeInfos(list []models.Theme) []*postv1.ThemeInfo {
+	res := make([]*postv1.ThemeInfo, len(list))
+
+	for i, theme := range list {
+		res[i] = &postv1.ThemeInfo{
+			ThemeId:    int64(theme.Id),
+			Name:       theme.Name,
+			PostsCount: int64(theme.PostsCount),
+		}
+	}
+
+	return res
+}
+
+// toInts converts transport ids to the service ones
+func toInts(ids []int64) []int {
+	res := make([]int, len(ids))
+	for i, id := range ids {
+		res[i] = int(id)
+	}
+
+	return res
+}
+
+// toPostInfos converts list of post models to its' transport representation
+func toPostInfos(list []models.Post) []*postv1.PostInfo {
+	res := make([]*postv1.PostInfo, len(list))
+
+	for i, post := range list {
+		res[i] = toPostInfo(post)
+	}
+
+	return res
+}
+
+// toPostInfo converts post model to its' transport representation
+func toPostInfo(post models.Post) *postv1.PostInfo {
+	info := &postv1.PostInfo{
+		PostId:        int64(post.Id),
+		UserId:        int64(post.UserId),
+		Login:         post.Login,
+		Header:        post.Header,
+		Content:       post.Content,
+		Themes:        post.Themes,
+		CreatedAt:     timestamppb.New(post.CreatedAt),
+		Status:        toPostStatus(post.Status),
+		Visibility:    toVisibility(post.Visibility),
+		Version:       int64(post.Version),
+		ContentFormat: toContentFormat(post.ContentFormat),
+		Rendered:      toRenderedContent(post.Rendered),
+		CommentsCount: int64(post.CommentsCount),
+		Views:         int64(post.Views),
+	}
+	if !post.DeletedAt.IsZero() {
+		info.DeletedAt = timestamppb.New(post.DeletedAt)
+	}
+	if !post.PublishAt.IsZero() {
+		info.PublishAt = timestamppb.New(post.PublishAt)
+	}
+
+	return info
+}
@@ -0,0 +1,60 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/posts"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// postServiceMock fails writes with err, other methods are not implemented
+type postServiceMock struct {
+	PostService
+	err error
+}
+
+func (p postServiceMock) Update(
+	ctx context.Context,
+	userId int,
+	postId int,
+	header string,
+	content string,
+	format models.ContentFormat,
+	themes []string,
+	mask models.UpdateMask,
+	addThemes []string,
+	removeThemes []string,
+	inferThemes bool,
+	version int,
+) ([]string, error) {
+	return nil, p.err
+}
+
+func (p postServiceMock) Delete(ctx context.Context, postId int, userId int, version int) error {
+	return p.err
+}
+
+func TestWriteConflict(t *testing.T) {
+	s := &ServerAPI{
+		srvc:    postServiceMock{err: fmt.Errorf("post-service.Update: %w", posts.ErrConflict)},
+		timeout: time.Second,
+	}
+
+	_, err := s.Update(t.Context(), &postv1.UpdateRequest{
+		PostId:  1,
+		UserId:  1,
+		Header:  "header",
+		Version: 3,
+	})
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = s.Delete(t.Context(), &postv1.DeleteRequest{PostId: 1, UserId: 1, Version: 3})
+	require.Equal(t, codes.Aborted, status.Code(err))
+}
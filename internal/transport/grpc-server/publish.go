@@ -0,0 +1,131 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/posts"
+	"github.com/IlianBuh/Post-service/internal/transport/validate"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PublishPost makes request to service layer to publish or schedule the unpublished post
+func (s *ServerAPI) PublishPost(
+	ctx context.Context,
+	req *postv1.PublishPostRequest,
+) (*postv1.PublishPostResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	err = s.srvc.Publish(
+		ctx,
+		int(req.GetPostId()),
+		int(req.GetUserId()),
+		toTime(req.GetPublishAt()),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, posts.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found")
+		case errors.Is(err, posts.ErrNotCreator):
+			return nil, status.Error(codes.PermissionDenied, "user is not creator")
+		case errors.Is(err, posts.ErrPublished):
+			return nil, status.Error(codes.FailedPrecondition, "post is already published")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.PublishPostResponse{}, nil
+}
+
+// ListUnpublished makes request to service layer to get page of users' drafts and scheduled posts
+func (s *ServerAPI) ListUnpublished(
+	ctx context.Context,
+	req *postv1.ListUnpublishedRequest,
+) (*postv1.ListUnpublishedResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, nextToken, err := s.srvc.ListUnpublished(
+		ctx,
+		int(req.GetUserId()),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		if errors.Is(err, posts.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.ListUnpublishedResponse{
+		Posts:         toPostInfos(list),
+		NextPageToken: nextToken,
+	}, nil
+}
+
+// postStatuses maps protobuf statuses to the domain ones.
+// Unspecified status is left to the service layer
+var postStatuses = map[postv1.PostStatus]models.PostStatus{
+	postv1.PostStatus_POST_STATUS_UNSPECIFIED: "",
+	postv1.PostStatus_POST_STATUS_DRAFT:       models.StatusDraft,
+	postv1.PostStatus_POST_STATUS_SCHEDULED:   models.StatusScheduled,
+	postv1.PostStatus_POST_STATUS_PUBLISHED:   models.StatusPublished,
+}
+
+// fromPostStatus converts protobuf status to the domain one.
+// Reports false if the status is unknown
+func fromPostStatus(st postv1.PostStatus) (models.PostStatus, bool) {
+	res, ok := postStatuses[st]
+	return res, ok
+}
+
+// toPostStatus converts domain status to the protobuf one
+func toPostStatus(st models.PostStatus) postv1.PostStatus {
+	for pbStatus, status := range postStatuses {
+		if status == st && status != "" {
+			return pbStatus
+		}
+	}
+
+	return postv1.PostStatus_POST_STATUS_UNSPECIFIED
+}
+
+// toTime converts the timestamp to time. Missing timestamp is converted to zero time
+func toTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+
+	return ts.AsTime()
+}
@@ -0,0 +1,160 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/reactions"
+	"github.com/IlianBuh/Post-service/internal/transport/validate"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// React makes request to service layer to set reaction of the viewer to the post
+func (s *ServerAPI) React(
+	ctx context.Context,
+	req *postv1.ReactRequest,
+) (*postv1.ReactResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	counts, err := s.reactions.React(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetPostId()),
+		req.GetKind(),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, reactions.ErrInvalidKind):
+			return nil, status.Error(codes.InvalidArgument, "unknown reaction kind")
+		case errors.Is(err, reactions.ErrUserNotFound):
+			return nil, status.Error(codes.InvalidArgument, "user does not exist")
+		case errors.Is(err, reactions.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.ReactResponse{Counts: toReactionCounts(counts)}, nil
+}
+
+// Unreact makes request to service layer to remove reaction of the user to the post
+func (s *ServerAPI) Unreact(
+	ctx context.Context,
+	req *postv1.UnreactRequest,
+) (*postv1.UnreactResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	counts, err := s.reactions.Unreact(ctx, int(req.GetUserId()), int(req.GetPostId()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.UnreactResponse{Counts: toReactionCounts(counts)}, nil
+}
+
+// ListReactors makes request to service layer to get page of reactions to the post
+func (s *ServerAPI) ListReactors(
+	ctx context.Context,
+	req *postv1.ListReactorsRequest,
+) (*postv1.ListReactorsResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, counts, nextToken, err := s.reactions.ListReactors(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetPostId()),
+		req.GetKind(),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, reactions.ErrInvalidKind):
+			return nil, status.Error(codes.InvalidArgument, "unknown reaction kind")
+		case errors.Is(err, reactions.ErrInvalidToken):
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		case errors.Is(err, reactions.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.ListReactorsResponse{
+		Reactors:      toReactorInfos(list),
+		Counts:        toReactionCounts(counts),
+		NextPageToken: nextToken,
+	}, nil
+}
+
+// toReactorInfos converts reactions to their transport representation
+func toReactorInfos(list []models.Reaction) []*postv1.ReactorInfo {
+	res := make([]*postv1.ReactorInfo, 0, len(list))
+	for _, r := range list {
+		res = append(res, &postv1.ReactorInfo{
+			UserId:    int64(r.UserId),
+			Kind:      r.Kind,
+			ReactedAt: timestamppb.New(r.ReactedAt),
+		})
+	}
+
+	return res
+}
+
+// toReactionCounts converts counters of reactions to their transport representation
+func toReactionCounts(counts []models.ReactionCount) []*postv1.ReactionCount {
+	res := make([]*postv1.ReactionCount, 0, len(counts))
+	for _, c := range counts {
+		res = append(res, &postv1.ReactionCount{
+			Kind:  c.Kind,
+			Count: int64(c.Count),
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,223 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/posts"
+	"github.com/IlianBuh/Post-service/internal/transport/validate"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ListRevisions makes request to service layer to get page of post revisions
+func (s *ServerAPI) ListRevisions(
+	ctx context.Context,
+	req *postv1.ListRevisionsRequest,
+) (*postv1.ListRevisionsResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, nextToken, err := s.srvc.ListRevisions(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetPostId()),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, posts.ErrInvalidToken):
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		case errors.Is(err, posts.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	res := make([]*postv1.RevisionInfo, len(list))
+	for i, rev := range list {
+		res[i] = toRevisionInfo(rev)
+	}
+
+	return &postv1.ListRevisionsResponse{Revisions: res, NextPageToken: nextToken}, nil
+}
+
+// GetRevision makes request to service layer to get the revision of the post
+func (s *ServerAPI) GetRevision(
+	ctx context.Context,
+	req *postv1.GetRevisionRequest,
+) (*postv1.GetRevisionResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Revision(req.GetRevision()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	rev, err := s.srvc.GetRevision(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetPostId()),
+		int(req.GetRevision()),
+	)
+	if err != nil {
+		if errors.Is(err, posts.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "revision not found")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.GetRevisionResponse{Revision: toRevisionInfo(rev)}, nil
+}
+
+// DiffRevisions makes request to service layer to compare two revisions of the post
+func (s *ServerAPI) DiffRevisions(
+	ctx context.Context,
+	req *postv1.DiffRevisionsRequest,
+) (*postv1.DiffRevisionsResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetFrom()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetTo()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Viewer(req.GetViewer().GetUserId(), req.GetViewer().GetFollowing()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	d, err := s.srvc.DiffRevisions(
+		ctx,
+		toViewer(req.GetViewer()),
+		int(req.GetPostId()),
+		int(req.GetFrom()),
+		int(req.GetTo()),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, posts.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "revision not found")
+		case errors.Is(err, posts.ErrTooLarge):
+			return nil, status.Error(codes.FailedPrecondition, "revisions are too large to diff")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.DiffRevisionsResponse{
+		Header:        toDiffLines(d.Header),
+		Content:       toDiffLines(d.Content),
+		AddedThemes:   d.AddedThemes,
+		RemovedThemes: d.RemovedThemes,
+	}, nil
+}
+
+// RestoreRevision makes request to service layer to restore the revision of the post
+func (s *ServerAPI) RestoreRevision(
+	ctx context.Context,
+	req *postv1.RestoreRevisionRequest,
+) (*postv1.RestoreRevisionResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Revision(req.GetRevision()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	err = s.srvc.RestoreRevision(
+		ctx,
+		int(req.GetUserId()),
+		int(req.GetPostId()),
+		int(req.GetRevision()),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, posts.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "revision not found")
+		case errors.Is(err, posts.ErrNotCreator):
+			return nil, status.Error(codes.PermissionDenied, "user is not creator")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.RestoreRevisionResponse{}, nil
+}
+
+// toRevisionInfo converts revision model to its' transport representation
+func toRevisionInfo(rev models.Revision) *postv1.RevisionInfo {
+	return &postv1.RevisionInfo{
+		PostId:        int64(rev.PostId),
+		Revision:      int64(rev.Revision),
+		Header:        rev.Header,
+		Content:       rev.Content,
+		Themes:        rev.Themes,
+		CreatedAt:     timestamppb.New(rev.CreatedAt),
+		ContentFormat: toContentFormat(rev.ContentFormat),
+	}
+}
+
+// toDiffLines converts diff lines to its' transport representation
+func toDiffLines(lines []models.DiffLine) []*postv1.DiffLine {
+	ops := map[models.DiffOp]postv1.DiffOp{
+		models.DiffEqual:  postv1.DiffOp_DIFF_OP_EQUAL,
+		models.DiffInsert: postv1.DiffOp_DIFF_OP_INSERT,
+		models.DiffDelete: postv1.DiffOp_DIFF_OP_DELETE,
+	}
+
+	res := make([]*postv1.DiffLine, len(lines))
+	for i, line := range lines {
+		res[i] = &postv1.DiffLine{Op: ops[line.Op], Text: line.Text}
+	}
+
+	return res
+}
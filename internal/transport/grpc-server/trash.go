@@ -0,0 +1,85 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlianBuh/Post-service/internal/service/posts"
+	"github.com/IlianBuh/Post-service/internal/transport/validate"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RestorePost makes request to service layer to take the deleted post out of trash
+func (s *ServerAPI) RestorePost(
+	ctx context.Context,
+	req *postv1.RestorePostRequest,
+) (*postv1.RestorePostResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	err = s.srvc.RestorePost(ctx, int(req.GetPostId()), int(req.GetUserId()))
+	if err != nil {
+		switch {
+		case errors.Is(err, posts.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "post not found in trash")
+		case errors.Is(err, posts.ErrNotCreator):
+			return nil, status.Error(codes.PermissionDenied, "user is not creator")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.RestorePostResponse{}, nil
+}
+
+// ListTrash makes request to service layer to get page of users' deleted posts
+func (s *ServerAPI) ListTrash(
+	ctx context.Context,
+	req *postv1.ListTrashRequest,
+) (*postv1.ListTrashResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetUserId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.PageSize(req.GetPageSize()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cnl := context.WithTimeout(ctx, s.timeout)
+	defer cnl()
+
+	list, nextToken, err := s.srvc.ListTrash(
+		ctx,
+		int(req.GetUserId()),
+		req.GetPageToken(),
+		int(req.GetPageSize()),
+	)
+	if err != nil {
+		if errors.Is(err, posts.ErrInvalidToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.ListTrashResponse{
+		Posts:         toPostInfos(list),
+		NextPageToken: nextToken,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package grpcserver
+
+import (
+	"fmt"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Paths of the post fields which can be named in the update mask
+const (
+	maskHeader  = "header"
+	maskContent = "content"
+	maskThemes  = "themes"
+)
+
+// fromUpdateMask converts protobuf field mask to the domain update mask.
+// Missing or empty field mask is converted to zero mask. Returns error
+// if the field mask names unknown field
+func fromUpdateMask(fm *fieldmaskpb.FieldMask) (models.UpdateMask, error) {
+	var mask models.UpdateMask
+	for _, path := range fm.GetPaths() {
+		switch path {
+		case maskHeader:
+			mask.Header = true
+		case maskContent:
+			mask.Content = true
+		case maskThemes:
+			mask.Themes = true
+		default:
+			return models.UpdateMask{}, fmt.Errorf("unknown field in update mask: %q", path)
+		}
+	}
+
+	return mask, nil
+}
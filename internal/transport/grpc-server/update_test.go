@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestFromUpdateMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		fm      *fieldmaskpb.FieldMask
+		want    models.UpdateMask
+		wantErr bool
+	}{
+		{name: "missing", fm: nil, want: models.UpdateMask{}},
+		{name: "empty", fm: &fieldmaskpb.FieldMask{}, want: models.UpdateMask{}},
+		{
+			name: "content",
+			fm:   &fieldmaskpb.FieldMask{Paths: []string{maskContent}},
+			want: models.UpdateMask{Content: true},
+		},
+		{
+			name: "all",
+			fm:   &fieldmaskpb.FieldMask{Paths: []string{maskHeader, maskContent, maskThemes}},
+			want: models.UpdateMask{Header: true, Content: true, Themes: true},
+		},
+		{
+			name:    "unknown",
+			fm:      &fieldmaskpb.FieldMask{Paths: []string{maskHeader, "login"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mask, err := fromUpdateMask(tt.fm)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, mask)
+		})
+	}
+}
+
+func TestUpdateUnknownMaskPath(t *testing.T) {
+	s := &ServerAPI{timeout: time.Second}
+
+	_, err := s.Update(t.Context(), &postv1.UpdateRequest{
+		PostId:     1,
+		UserId:     1,
+		Header:     "header",
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"login"}},
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
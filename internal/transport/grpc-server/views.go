@@ -0,0 +1,35 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/IlianBuh/Post-service/internal/transport/validate"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecordView makes request to service layer to count the view of the post
+func (s *ServerAPI) RecordView(
+	ctx context.Context,
+	req *postv1.RecordViewRequest,
+) (*postv1.RecordViewResponse, error) {
+	var err error
+	if err = ctx.Err(); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = validate.Id(req.GetPostId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = validate.Id(req.GetViewerId()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = s.views.RecordView(ctx, int(req.GetPostId()), int(req.GetViewerId()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	return &postv1.RecordViewResponse{}, nil
+}
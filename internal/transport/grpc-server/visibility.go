@@ -0,0 +1,43 @@
+package grpcserver
+
+import (
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	postv1 "github.com/IlianBuh/Posts-Protobuf/gen/go"
+)
+
+// visibilities maps protobuf visibilities to the domain ones.
+// Unspecified visibility is left to the service layer
+var visibilities = map[postv1.Visibility]models.Visibility{
+	postv1.Visibility_VISIBILITY_UNSPECIFIED: "",
+	postv1.Visibility_VISIBILITY_PUBLIC:      models.VisibilityPublic,
+	postv1.Visibility_VISIBILITY_UNLISTED:    models.VisibilityUnlisted,
+	postv1.Visibility_VISIBILITY_FOLLOWERS:   models.VisibilityFollowers,
+	postv1.Visibility_VISIBILITY_PRIVATE:     models.VisibilityPrivate,
+}
+
+// fromVisibility converts protobuf visibility to the domain one.
+// Reports false if the visibility is unknown
+func fromVisibility(v postv1.Visibility) (models.Visibility, bool) {
+	res, ok := visibilities[v]
+	return res, ok
+}
+
+// toVisibility converts domain visibility to the protobuf one
+func toVisibility(v models.Visibility) postv1.Visibility {
+	for pbVisibility, visibility := range visibilities {
+		if visibility == v && visibility != "" {
+			return pbVisibility
+		}
+	}
+
+	return postv1.Visibility_VISIBILITY_UNSPECIFIED
+}
+
+// toViewer converts protobuf viewer to the domain one.
+// Missing viewer is anonymous
+func toViewer(v *postv1.Viewer) models.Viewer {
+	return models.Viewer{
+		Id:        int(v.GetUserId()),
+		Following: toInts(v.GetFollowing()),
+	}
+}
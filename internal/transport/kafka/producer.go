@@ -14,6 +14,9 @@ import (
 
 const (
 	initialRetryTime = 0
+	// typeHeader is a header keeping type of the event,
+	// all types are sent to the same topic
+	typeHeader = "type"
 )
 
 var (
@@ -120,6 +123,9 @@ func (p *Producer) Send(ctx context.Context, page []models.Event) error {
 			Value:     sarama.ByteEncoder(eventmsg),
 			Key:       sarama.ByteEncoder(event.Id),
 			Timestamp: time.Now(),
+			Headers: []sarama.RecordHeader{
+				{Key: []byte(typeHeader), Value: []byte(event.Type)},
+			},
 		}
 
 		select {
@@ -11,7 +11,9 @@ import (
 	"github.com/IlianBuh/Post-service/internal/lib/logger/sl"
 	userinfov1 "github.com/IlianBuh/SSO_Protobuf/gen/go/userinfo"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 type UserProvider struct {
@@ -65,6 +67,34 @@ func (u *UserProvider) Exists(ctx context.Context, uuid int) (isExists bool, err
 	return resp.Exist, nil
 }
 
+// UserByLogin returns id of the user with exactly the login. Missing user
+// is not an error
+func (u *UserProvider) UserByLogin(
+	ctx context.Context,
+	login string,
+) (uuid int, isExists bool, err error) {
+	const op = "user-provider.UserByLogin"
+
+	resp, err := u.UserClient.UsersByLogin(
+		ctx,
+		&userinfov1.UsersByLoginRequest{Login: login},
+	)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return 0, false, nil
+		}
+		return 0, false, errors.Fail(op, err)
+	}
+
+	for _, usr := range resp.GetUsers() {
+		if usr.GetLogin() == login {
+			return int(usr.GetUuid()), true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
 func (u *UserProvider) Stop() {
 	const op = "user-provider.Stop"
 	log := u.log.With(slog.String("op", op))
@@ -2,6 +2,7 @@ package validate
 
 import (
 	"fmt"
+	"strings"
 )
 
 func Header(header string) error {
@@ -19,3 +20,165 @@ func Id(id int64) error {
 
 	return nil
 }
+
+// UserId checks id of the user acting on its' own data,
+// zero id of the anonymous viewer is rejected
+func UserId(id int64) error {
+	if id <= 0 {
+		return fmt.Errorf("%s", "user id must be positive number")
+	}
+
+	return nil
+}
+
+func Ids(ids []int64) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("%s", "ids can't be empty")
+	}
+
+	for _, id := range ids {
+		if err := Id(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// maxFollowing limits number of authors followed by a viewer
+const maxFollowing = 1000
+
+func Viewer(userId int64, following []int64) error {
+	if err := Id(userId); err != nil {
+		return err
+	}
+	if len(following) > maxFollowing {
+		return fmt.Errorf("viewer can't follow more than %d authors", maxFollowing)
+	}
+
+	for _, id := range following {
+		if err := Id(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Revision(revision int64) error {
+	if revision <= 0 {
+		return fmt.Errorf("%s", "revision must be positive number")
+	}
+
+	return nil
+}
+
+func Version(version int64) error {
+	if version < 0 {
+		return fmt.Errorf("%s", "version can't be negative")
+	}
+
+	return nil
+}
+
+// maxFileNameLen limits length of a name of the attached file
+const maxFileNameLen = 255
+
+func FileName(name string) error {
+	if len(strings.TrimSpace(name)) == 0 {
+		return fmt.Errorf("%s", "file name can't be empty")
+	}
+	if len(name) > maxFileNameLen {
+		return fmt.Errorf("file name can't be longer than %d bytes", maxFileNameLen)
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, "/\\\x00") {
+		return fmt.Errorf("%s", "file name can't be a path")
+	}
+
+	return nil
+}
+
+// maxIdempotencyKeyLen limits length of an idempotency key
+const maxIdempotencyKeyLen = 128
+
+func IdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLen {
+		return fmt.Errorf("idempotency key can't be longer than %d bytes", maxIdempotencyKeyLen)
+	}
+
+	return nil
+}
+
+func PageSize(size int32) error {
+	if size < 0 {
+		return fmt.Errorf("%s", "page size can't be negative")
+	}
+
+	return nil
+}
+
+func Themes(themes []string) error {
+	if len(themes) == 0 {
+		return fmt.Errorf("%s", "themes can't be empty")
+	}
+
+	for _, theme := range themes {
+		if len(theme) == 0 {
+			return fmt.Errorf("%s", "theme can't be empty")
+		}
+	}
+
+	return nil
+}
+
+func Query(query string) error {
+	if len(strings.TrimSpace(query)) == 0 {
+		return fmt.Errorf("%s", "query can't be empty")
+	}
+
+	return nil
+}
+
+func Prefix(prefix string) error {
+	if len(strings.TrimSpace(prefix)) == 0 {
+		return fmt.Errorf("%s", "prefix can't be empty")
+	}
+
+	return nil
+}
+
+func Theme(theme string) error {
+	if len(strings.TrimSpace(theme)) == 0 {
+		return fmt.Errorf("%s", "theme can't be empty")
+	}
+
+	return nil
+}
+
+// maxCommentLen limits length of a comment
+const maxCommentLen = 10000
+
+func Comment(content string) error {
+	if len(strings.TrimSpace(content)) == 0 {
+		return fmt.Errorf("%s", "comment can't be empty")
+	}
+	if len(content) > maxCommentLen {
+		return fmt.Errorf("comment can't be longer than %d bytes", maxCommentLen)
+	}
+
+	return nil
+}
+
+// maxListNameLen limits length of a name of the reading list
+const maxListNameLen = 64
+
+func ListName(name string) error {
+	if len(name) > maxListNameLen {
+		return fmt.Errorf("list name can't be longer than %d bytes", maxListNameLen)
+	}
+	if name != strings.TrimSpace(name) {
+		return fmt.Errorf("%s", "list name can't start or end with spaces")
+	}
+
+	return nil
+}
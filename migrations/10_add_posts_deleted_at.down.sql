@@ -0,0 +1,17 @@
+DROP MATERIALIZED VIEW IF EXISTS theme_hourly_posts;
+CREATE MATERIALIZED VIEW theme_hourly_posts AS
+SELECT pt.theme_id, DATE_TRUNC('hour', p.created_at) AS hour, COUNT(*) AS posts_count
+FROM post_theme pt
+JOIN posts p ON p.post_id = pt.post_id
+WHERE p.created_at >= NOW() - INTERVAL '30 days'
+GROUP BY pt.theme_id, DATE_TRUNC('hour', p.created_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS theme_hourly_posts_theme_hour_idx
+ON theme_hourly_posts (theme_id, hour);
+
+CREATE INDEX IF NOT EXISTS theme_hourly_posts_hour_idx
+ON theme_hourly_posts (hour);
+
+DROP INDEX IF EXISTS posts_deleted_idx;
+DROP INDEX IF EXISTS posts_user_deleted_idx;
+ALTER TABLE posts DROP COLUMN IF EXISTS deleted_at;
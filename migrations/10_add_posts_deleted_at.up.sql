@@ -0,0 +1,26 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- trash of the user ordered from the most recently deleted
+CREATE INDEX IF NOT EXISTS posts_user_deleted_idx
+ON posts (user_id, deleted_at DESC, post_id DESC)
+WHERE deleted_at IS NOT NULL;
+
+-- trashed posts are looked up by the purger
+CREATE INDEX IF NOT EXISTS posts_deleted_idx
+ON posts (deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+-- trashed posts must not be counted in trends
+DROP MATERIALIZED VIEW IF EXISTS theme_hourly_posts;
+CREATE MATERIALIZED VIEW theme_hourly_posts AS
+SELECT pt.theme_id, DATE_TRUNC('hour', p.created_at) AS hour, COUNT(*) AS posts_count
+FROM post_theme pt
+JOIN posts p ON p.post_id = pt.post_id
+WHERE p.created_at >= NOW() - INTERVAL '30 days' AND p.deleted_at IS NULL
+GROUP BY pt.theme_id, DATE_TRUNC('hour', p.created_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS theme_hourly_posts_theme_hour_idx
+ON theme_hourly_posts (theme_id, hour);
+
+CREATE INDEX IF NOT EXISTS theme_hourly_posts_hour_idx
+ON theme_hourly_posts (hour);
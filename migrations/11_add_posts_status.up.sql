@@ -0,0 +1,30 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS "status" TEXT NOT NULL DEFAULT 'published'
+    CHECK ("status" IN ('draft', 'scheduled', 'published'));
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+
+-- due scheduled posts are looked up by the scheduler
+CREATE INDEX IF NOT EXISTS posts_scheduled_publish_idx
+ON posts (publish_at)
+WHERE "status" = 'scheduled';
+
+-- unpublished posts of the user are listed by their authors
+CREATE INDEX IF NOT EXISTS posts_user_unpublished_idx
+ON posts (user_id, created_at DESC, post_id DESC)
+WHERE "status" <> 'published';
+
+-- unpublished posts must not be counted in trends
+DROP MATERIALIZED VIEW IF EXISTS theme_hourly_posts;
+CREATE MATERIALIZED VIEW theme_hourly_posts AS
+SELECT pt.theme_id, DATE_TRUNC('hour', p.created_at) AS hour, COUNT(*) AS posts_count
+FROM post_theme pt
+JOIN posts p ON p.post_id = pt.post_id
+WHERE p.created_at >= NOW() - INTERVAL '30 days'
+    AND p.deleted_at IS NULL
+    AND p."status" = 'published'
+GROUP BY pt.theme_id, DATE_TRUNC('hour', p.created_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS theme_hourly_posts_theme_hour_idx
+ON theme_hourly_posts (theme_id, hour);
+
+CREATE INDEX IF NOT EXISTS theme_hourly_posts_hour_idx
+ON theme_hourly_posts (hour);
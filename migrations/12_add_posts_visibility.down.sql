@@ -0,0 +1,18 @@
+DROP MATERIALIZED VIEW IF EXISTS theme_hourly_posts;
+CREATE MATERIALIZED VIEW theme_hourly_posts AS
+SELECT pt.theme_id, DATE_TRUNC('hour', p.created_at) AS hour, COUNT(*) AS posts_count
+FROM post_theme pt
+JOIN posts p ON p.post_id = pt.post_id
+WHERE p.created_at >= NOW() - INTERVAL '30 days'
+    AND p.deleted_at IS NULL
+    AND p."status" = 'published'
+GROUP BY pt.theme_id, DATE_TRUNC('hour', p.created_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS theme_hourly_posts_theme_hour_idx
+ON theme_hourly_posts (theme_id, hour);
+
+CREATE INDEX IF NOT EXISTS theme_hourly_posts_hour_idx
+ON theme_hourly_posts (hour);
+
+DROP FUNCTION IF EXISTS post_visible(TEXT, INT, INT, INT[], BOOLEAN);
+ALTER TABLE posts DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,39 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'followers', 'private'));
+
+-- post_visible reports whether the viewer can read the post. Zero viewer is
+-- anonymous, following contains authors followed by the viewer. Unlisted posts
+-- are readable by id but are not listed
+CREATE OR REPLACE FUNCTION post_visible(
+    visibility TEXT,
+    author_id INT,
+    viewer_id INT,
+    following INT[],
+    listed BOOLEAN
+) RETURNS BOOLEAN
+LANGUAGE SQL IMMUTABLE AS $$
+    SELECT author_id = viewer_id OR CASE visibility
+        WHEN 'public' THEN TRUE
+        WHEN 'unlisted' THEN NOT listed
+        WHEN 'followers' THEN COALESCE(author_id = ANY(following), FALSE)
+        ELSE FALSE
+    END;
+$$;
+
+-- only public posts are counted in trends
+DROP MATERIALIZED VIEW IF EXISTS theme_hourly_posts;
+CREATE MATERIALIZED VIEW theme_hourly_posts AS
+SELECT pt.theme_id, DATE_TRUNC('hour', p.created_at) AS hour, COUNT(*) AS posts_count
+FROM post_theme pt
+JOIN posts p ON p.post_id = pt.post_id
+WHERE p.created_at >= NOW() - INTERVAL '30 days'
+    AND p.deleted_at IS NULL
+    AND p."status" = 'published'
+    AND p.visibility = 'public'
+GROUP BY pt.theme_id, DATE_TRUNC('hour', p.created_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS theme_hourly_posts_theme_hour_idx
+ON theme_hourly_posts (theme_id, hour);
+
+CREATE INDEX IF NOT EXISTS theme_hourly_posts_hour_idx
+ON theme_hourly_posts (hour);
@@ -0,0 +1,13 @@
+-- post_id is empty until the post is saved in the same transaction
+CREATE TABLE IF NOT EXISTS idempotency_keys(
+    user_id INT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    post_id INT REFERENCES posts(post_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+-- expired keys are looked up by the purger
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_idx
+ON idempotency_keys (created_at);
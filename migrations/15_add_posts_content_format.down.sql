@@ -0,0 +1,8 @@
+ALTER TABLE post_revisions DROP COLUMN IF EXISTS content_format;
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS reading_seconds,
+    DROP COLUMN IF EXISTS word_count,
+    DROP COLUMN IF EXISTS excerpt,
+    DROP COLUMN IF EXISTS content_html,
+    DROP COLUMN IF EXISTS content_format;
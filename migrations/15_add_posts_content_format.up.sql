@@ -0,0 +1,21 @@
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS content_format TEXT NOT NULL DEFAULT 'plain'
+        CHECK (content_format IN ('plain', 'markdown')),
+    ADD COLUMN IF NOT EXISTS content_html TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS excerpt TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS word_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS reading_seconds INT NOT NULL DEFAULT 0;
+
+-- existing posts are plain text, the service renders new ones
+UPDATE posts SET
+    excerpt = LEFT(regexp_replace(regexp_replace(content, '^\s+|\s+$', '', 'g'), '\s+', ' ', 'g'), 200),
+    word_count = array_length(
+        regexp_split_to_array(regexp_replace(content, '^\s+|\s+$', '', 'g'), '\s+'), 1
+    )
+WHERE content ~ '\S';
+
+UPDATE posts SET reading_seconds = CEIL(word_count / 200.0)::INT * 60
+WHERE word_count > 0;
+
+ALTER TABLE post_revisions
+    ADD COLUMN IF NOT EXISTS content_format TEXT NOT NULL DEFAULT 'plain';
@@ -0,0 +1,17 @@
+-- post_id is cleared when the post is purged, so the purger
+-- can find orphaned attachments and delete their blobs
+CREATE TABLE IF NOT EXISTS attachments(
+    attachment_id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    post_id INT REFERENCES posts(post_id) ON DELETE SET NULL,
+    file_name TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    mime_type TEXT NOT NULL,
+    sha256 TEXT NOT NULL,
+    blob_key TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (post_id, file_name)
+);
+
+CREATE INDEX IF NOT EXISTS attachments_orphaned_idx
+ON attachments (attachment_id)
+WHERE post_id IS NULL;
@@ -0,0 +1,12 @@
+DELETE FROM events WHERE "type" = 'thumbnails_ready';
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check;
+ALTER TABLE events ADD CONSTRAINT events_type_check CHECK ("type" IN ('created'));
+
+DROP TABLE IF EXISTS attachment_variants;
+
+DROP INDEX IF EXISTS attachments_pending_idx;
+
+ALTER TABLE attachments
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS image_status;
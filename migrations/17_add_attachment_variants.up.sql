@@ -0,0 +1,27 @@
+ALTER TABLE attachments
+    ADD COLUMN IF NOT EXISTS image_status TEXT
+        CHECK (image_status IN ('pending', 'ready', 'failed')),
+    ADD COLUMN IF NOT EXISTS width INT,
+    ADD COLUMN IF NOT EXISTS height INT;
+
+UPDATE attachments SET image_status = 'pending'
+WHERE mime_type IN ('image/jpeg', 'image/png', 'image/gif');
+
+CREATE INDEX IF NOT EXISTS attachments_pending_idx
+ON attachments (attachment_id)
+WHERE image_status = 'pending';
+
+CREATE TABLE IF NOT EXISTS attachment_variants(
+    attachment_id INT NOT NULL REFERENCES attachments(attachment_id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    size BIGINT NOT NULL,
+    mime_type TEXT NOT NULL,
+    blob_key TEXT UNIQUE NOT NULL,
+    PRIMARY KEY (attachment_id, name)
+);
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check;
+ALTER TABLE events ADD CONSTRAINT events_type_check
+    CHECK ("type" IN ('created', 'thumbnails_ready'));
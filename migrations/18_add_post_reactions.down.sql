@@ -0,0 +1,7 @@
+DELETE FROM events WHERE "type" = 'reacted';
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check;
+ALTER TABLE events ADD CONSTRAINT events_type_check
+    CHECK ("type" IN ('created', 'thumbnails_ready'));
+
+DROP TABLE IF EXISTS post_reaction_counts;
+DROP TABLE IF EXISTS post_reactions;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS post_reactions(
+    post_id INT NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    kind TEXT NOT NULL,
+    reacted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS post_reactions_recent_idx
+ON post_reactions (post_id, reacted_at DESC, user_id DESC);
+
+CREATE TABLE IF NOT EXISTS post_reaction_counts(
+    post_id INT NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    count INT NOT NULL DEFAULT 0 CHECK (count >= 0),
+    PRIMARY KEY (post_id, kind)
+);
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check;
+ALTER TABLE events ADD CONSTRAINT events_type_check
+    CHECK ("type" IN ('created', 'thumbnails_ready', 'reacted'));
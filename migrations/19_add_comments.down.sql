@@ -0,0 +1,9 @@
+DELETE FROM events WHERE "type" = 'comment_created';
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check;
+ALTER TABLE events ADD CONSTRAINT events_type_check
+    CHECK ("type" IN ('created', 'thumbnails_ready', 'reacted'));
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS comments_count;
+
+DROP TABLE IF EXISTS comments;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS comments(
+    comment_id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    post_id INT NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    parent_id INT REFERENCES comments(comment_id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    content TEXT NOT NULL,
+    replies_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS comments_top_level_idx
+ON comments (post_id, created_at, comment_id)
+WHERE parent_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS comments_replies_idx
+ON comments (parent_id, created_at, comment_id);
+
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS comments_count INT NOT NULL DEFAULT 0;
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check;
+ALTER TABLE events ADD CONSTRAINT events_type_check
+    CHECK ("type" IN ('created', 'thumbnails_ready', 'reacted', 'comment_created'));
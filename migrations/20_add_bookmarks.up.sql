@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS bookmarks(
+    user_id INT NOT NULL,
+    list_name TEXT NOT NULL,
+    post_id INT NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, list_name, post_id)
+);
+
+CREATE INDEX IF NOT EXISTS bookmarks_recent_idx
+ON bookmarks (user_id, list_name, created_at DESC, post_id DESC);
+
+CREATE INDEX IF NOT EXISTS bookmarks_post_idx
+ON bookmarks (post_id);
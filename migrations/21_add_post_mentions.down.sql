@@ -0,0 +1,6 @@
+DELETE FROM events WHERE "type" = 'mentioned';
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_type_check;
+ALTER TABLE events ADD CONSTRAINT events_type_check
+    CHECK ("type" IN ('created', 'thumbnails_ready', 'reacted', 'comment_created'));
+
+DROP TABLE IF EXISTS post_mentions;
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS post_stats(
+    post_id INT PRIMARY KEY REFERENCES posts(post_id) ON DELETE CASCADE,
+    views BIGINT NOT NULL DEFAULT 0 CHECK (views >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS attachments_pending_idx;
+CREATE INDEX IF NOT EXISTS attachments_pending_idx
+ON attachments (attachment_id)
+WHERE image_status = 'pending';
+
+ALTER TABLE attachments
+    DROP COLUMN IF EXISTS image_attempts;
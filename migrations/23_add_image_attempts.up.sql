@@ -0,0 +1,7 @@
+ALTER TABLE attachments
+    ADD COLUMN IF NOT EXISTS image_attempts INT NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS attachments_pending_idx;
+CREATE INDEX IF NOT EXISTS attachments_pending_idx
+ON attachments (image_attempts, attachment_id)
+WHERE image_status = 'pending';
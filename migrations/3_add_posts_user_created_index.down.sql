@@ -0,0 +1 @@
+DROP INDEX IF EXISTS posts_user_created_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS posts_user_created_idx
+ON posts (user_id, created_at DESC, post_id DESC);
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS post_theme_theme_idx
+ON post_theme (theme_id, post_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS posts_search_idx;
+
+ALTER TABLE posts
+DROP COLUMN IF EXISTS search_vector;
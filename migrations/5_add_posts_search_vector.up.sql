@@ -0,0 +1,8 @@
+ALTER TABLE posts
+ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', header), 'A') ||
+    setweight(to_tsvector('english', content), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS posts_search_idx
+ON posts USING GIN (search_vector);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS themes_name_prefix_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS themes_name_prefix_idx
+ON themes (LOWER(theme_name) text_pattern_ops);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS theme_aliases;
+
+ALTER TABLE themes
+DROP CONSTRAINT IF EXISTS themes_theme_name_key,
+ALTER COLUMN theme_name DROP NOT NULL;
@@ -0,0 +1,36 @@
+-- LOWER is used as the closest approximation of case folding done by the service
+UPDATE themes
+SET theme_name = NORMALIZE(LOWER(BTRIM(REGEXP_REPLACE(theme_name, '\s+', ' ', 'g'))), NFC)
+WHERE theme_name IS NOT NULL;
+
+DELETE FROM themes
+WHERE theme_name IS NULL OR theme_name = '';
+
+-- move relations of duplicates to the oldest theme with the same name
+INSERT INTO post_theme(post_id, theme_id)
+SELECT pt.post_id, c.theme_id
+FROM post_theme pt
+JOIN themes t ON t.theme_id = pt.theme_id
+JOIN (
+    SELECT theme_name, MIN(theme_id) AS theme_id
+    FROM themes
+    GROUP BY theme_name
+) c ON c.theme_name = t.theme_name
+WHERE t.theme_id <> c.theme_id
+ON CONFLICT DO NOTHING;
+
+DELETE FROM themes t
+USING themes c
+WHERE t.theme_name = c.theme_name AND t.theme_id > c.theme_id;
+
+ALTER TABLE themes
+ALTER COLUMN theme_name SET NOT NULL,
+ADD CONSTRAINT themes_theme_name_key UNIQUE (theme_name);
+
+CREATE TABLE IF NOT EXISTS theme_aliases(
+    alias TEXT PRIMARY KEY,
+    theme_id INT NOT NULL REFERENCES themes(theme_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS theme_aliases_theme_idx
+ON theme_aliases (theme_id);
@@ -0,0 +1 @@
+DROP MATERIALIZED VIEW IF EXISTS theme_hourly_posts;
@@ -0,0 +1,14 @@
+-- keeps 30 days of history, enough for the longest trending window with its baseline
+CREATE MATERIALIZED VIEW IF NOT EXISTS theme_hourly_posts AS
+SELECT pt.theme_id, DATE_TRUNC('hour', p.created_at) AS hour, COUNT(*) AS posts_count
+FROM post_theme pt
+JOIN posts p ON p.post_id = pt.post_id
+WHERE p.created_at >= NOW() - INTERVAL '30 days'
+GROUP BY pt.theme_id, DATE_TRUNC('hour', p.created_at);
+
+-- unique index is required to refresh the view concurrently
+CREATE UNIQUE INDEX IF NOT EXISTS theme_hourly_posts_theme_hour_idx
+ON theme_hourly_posts (theme_id, hour);
+
+CREATE INDEX IF NOT EXISTS theme_hourly_posts_hour_idx
+ON theme_hourly_posts (hour);
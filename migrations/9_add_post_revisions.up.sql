@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS post_revisions(
+    post_id INT NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    header TEXT NOT NULL,
+    content TEXT NOT NULL,
+    themes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, revision)
+);
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/IlianBuh/Post-service/internal/config"
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/bookmarks"
+	"github.com/IlianBuh/Post-service/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBookmarksPaging bookmarks posts into the reading lists and reads
+// the list page by page, most recently added first
+func TestBookmarksPaging(t *testing.T) {
+	s := suite.NewSuite(t, config.MustLoad(configPath))
+	ctx := t.Context()
+	userId := int(gofakeit.Uint16()) + 1
+	viewer := models.Viewer{Id: userId}
+	// names are unique, so bookmarks of the previous runs are not counted
+	list, later := gofakeit.UUID(), gofakeit.UUID()
+
+	postIds := make([]int, 5)
+	for i := range postIds {
+		postIds[i] = createPost(t, s, userId, models.FormatPlain, gofakeit.Sentence(10))
+		require.NoError(t, s.Bookmark.Add(ctx, viewer, list, postIds[i]))
+	}
+	// bookmarking twice is not an error and adds nothing
+	require.NoError(t, s.Bookmark.Add(ctx, viewer, list, postIds[0]))
+	require.NoError(t, s.Bookmark.Add(ctx, viewer, later, postIds[0]))
+
+	var got []int
+	token := ""
+	for {
+		page, next, err := s.Bookmark.List(ctx, viewer, list, token, 2)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 2)
+
+		for _, bookmark := range page {
+			require.Equal(t, list, bookmark.List)
+			got = append(got, bookmark.Post.Id)
+		}
+
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	require.Equal(t, []int{postIds[4], postIds[3], postIds[2], postIds[1], postIds[0]}, got)
+
+	lists, err := s.Bookmark.Lists(ctx, userId)
+	require.NoError(t, err)
+	require.Contains(t, lists, models.BookmarkList{Name: list, Count: 5})
+	require.Contains(t, lists, models.BookmarkList{Name: later, Count: 1})
+
+	require.NoError(t, s.Bookmark.Remove(ctx, userId, list, postIds[2]))
+	// removing missing bookmark is not an error
+	require.NoError(t, s.Bookmark.Remove(ctx, userId, list, postIds[2]))
+
+	page, next, err := s.Bookmark.List(ctx, viewer, list, "", 0)
+	require.NoError(t, err)
+	require.Empty(t, next)
+	require.Len(t, page, 4)
+	for _, bookmark := range page {
+		require.NotEqual(t, postIds[2], bookmark.Post.Id)
+	}
+}
+
+func TestBookmarkDefaultList(t *testing.T) {
+	s := suite.NewSuite(t, config.MustLoad(configPath))
+	ctx := t.Context()
+	userId := int(gofakeit.Uint16()) + 1
+	viewer := models.Viewer{Id: userId}
+	postId := createPost(t, s, userId, models.FormatPlain, gofakeit.Sentence(10))
+
+	require.NoError(t, s.Bookmark.Add(ctx, viewer, "", postId))
+
+	page, _, err := s.Bookmark.List(ctx, viewer, bookmarks.DefaultList, "", 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, page)
+	require.Equal(t, postId, page[0].Post.Id)
+	require.Equal(t, bookmarks.DefaultList, page[0].List)
+}
+
+func TestBookmarkInvalid(t *testing.T) {
+	s := suite.NewSuite(t, config.MustLoad(configPath))
+	ctx := t.Context()
+	viewer := models.Viewer{Id: int(gofakeit.Uint16()) + 1}
+
+	require.ErrorIs(t, s.Bookmark.Add(ctx, viewer, "", 0), bookmarks.ErrNotFound)
+
+	_, _, err := s.Bookmark.List(ctx, viewer, "", "not a token", 0)
+	require.ErrorIs(t, err, bookmarks.ErrInvalidToken)
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IlianBuh/Post-service/internal/config"
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/comments"
+	"github.com/IlianBuh/Post-service/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeleteCommentThread deletes comments of the thread A -> B -> C
+// from the middle and checks that live replies are never hidden
+func TestDeleteCommentThread(t *testing.T) {
+	s := suite.NewSuite(t, config.MustLoad(configPath))
+	ctx := t.Context()
+	userId := int(gofakeit.Uint16()) + 1
+	viewer := models.Viewer{Id: userId}
+	postId := createPost(t, s, userId, models.FormatPlain, gofakeit.Sentence(10))
+
+	a, err := s.Comment.Create(ctx, viewer, postId, 0, "a")
+	require.NoError(t, err)
+	b, err := s.Comment.Create(ctx, viewer, postId, a.Id, "b")
+	require.NoError(t, err)
+	c, err := s.Comment.Create(ctx, viewer, postId, b.Id, "c")
+	require.NoError(t, err)
+
+	// B keeps C, so A still has a shown reply
+	require.NoError(t, s.Comment.Delete(ctx, b.Id, userId))
+	top, _, err := s.Comment.List(ctx, viewer, postId, 0, "", 0)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	require.Equal(t, a.Id, top[0].Id)
+	require.Equal(t, 1, top[0].RepliesCount)
+	require.Len(t, top[0].Replies, 1)
+	require.Equal(t, b.Id, top[0].Replies[0].Id)
+
+	// A is deleted, but C is alive, so the whole thread is shown
+	require.NoError(t, s.Comment.Delete(ctx, a.Id, userId))
+	top, _, err = s.Comment.List(ctx, viewer, postId, 0, "", 0)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	require.Equal(t, a.Id, top[0].Id)
+	replies, _, err := s.Comment.List(ctx, viewer, postId, b.Id, "", 0)
+	require.NoError(t, err)
+	require.Len(t, replies, 1)
+	require.Equal(t, c.Id, replies[0].Id)
+
+	// the last live comment is deleted, the thread is hidden
+	require.NoError(t, s.Comment.Delete(ctx, c.Id, userId))
+	top, _, err = s.Comment.List(ctx, viewer, postId, 0, "", 0)
+	require.NoError(t, err)
+	require.Empty(t, top)
+}
+
+// TestCommentLifecycle edits and deletes comments of the post and checks
+// creator checks, replies and the comments counter of the post
+func TestCommentLifecycle(t *testing.T) {
+	s := suite.NewSuite(t, config.MustLoad(configPath))
+	ctx := t.Context()
+	userId := int(gofakeit.Uint16()) + 1
+	otherId := userId + 1
+	viewer := models.Viewer{Id: userId}
+	postId := createPost(t, s, userId, models.FormatPlain, gofakeit.Sentence(10))
+	otherPostId := createPost(t, s, userId, models.FormatPlain, gofakeit.Sentence(10))
+
+	top, err := s.Comment.Create(ctx, viewer, postId, 0, "top")
+	require.NoError(t, err)
+	reply, err := s.Comment.Create(ctx, models.Viewer{Id: otherId}, postId, top.Id, "reply")
+	require.NoError(t, err)
+	require.Equal(t, top.Id, reply.ParentId)
+
+	// parent must be a comment of the same post
+	_, err = s.Comment.Create(ctx, viewer, otherPostId, top.Id, "elsewhere")
+	require.ErrorIs(t, err, comments.ErrParentNotFound)
+
+	_, err = s.Comment.Edit(ctx, top.Id, otherId, "stolen")
+	require.ErrorIs(t, err, comments.ErrNotCreator)
+	require.ErrorIs(t, s.Comment.Delete(ctx, top.Id, otherId), comments.ErrNotCreator)
+
+	edited, err := s.Comment.Edit(ctx, top.Id, userId, "edited")
+	require.NoError(t, err)
+	require.Equal(t, "edited", edited.Content)
+	require.False(t, edited.UpdatedAt.IsZero())
+
+	post, err := s.Post.Get(ctx, viewer, postId)
+	require.NoError(t, err)
+	require.Equal(t, 2, post.CommentsCount)
+
+	list, _, err := s.Comment.List(ctx, viewer, postId, 0, "", 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, "edited", list[0].Content)
+	require.Equal(t, 1, list[0].RepliesCount)
+	require.Len(t, list[0].Replies, 1)
+	require.Equal(t, reply.Id, list[0].Replies[0].Id)
+
+	require.NoError(t, s.Comment.Delete(ctx, reply.Id, otherId))
+	_, err = s.Comment.Edit(ctx, reply.Id, otherId, "again")
+	require.ErrorIs(t, err, comments.ErrNotFound)
+
+	post, err = s.Post.Get(ctx, viewer, postId)
+	require.NoError(t, err)
+	require.Equal(t, 1, post.CommentsCount)
+
+	list, _, err = s.Comment.List(ctx, viewer, postId, 0, "", 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Zero(t, list[0].RepliesCount)
+	require.Empty(t, list[0].Replies)
+}
+
+// createPost creates published public post of the user and returns its' id
+func createPost(
+	t *testing.T,
+	s *suite.Suite,
+	userId int,
+	format models.ContentFormat,
+	content string,
+) int {
+	t.Helper()
+
+	postId, _, err := s.Post.Create(
+		t.Context(),
+		userId,
+		gofakeit.Username(),
+		gofakeit.Sentence(5),
+		content,
+		format,
+		generateThemes(),
+		false,
+		models.StatusPublished,
+		time.Time{},
+		models.VisibilityPublic,
+		"",
+	)
+	require.NoError(t, err)
+
+	return postId
+}
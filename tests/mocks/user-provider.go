@@ -7,3 +7,7 @@ type UserMock struct{}
 func (UserMock) Exists(ctx context.Context, uuid int) (isExists bool, err error) {
 	return true, nil
 }
+
+func (UserMock) UserByLogin(ctx context.Context, login string) (uuid int, isExists bool, err error) {
+	return 0, false, nil
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/IlianBuh/Post-service/internal/config"
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/posts"
+	"github.com/IlianBuh/Post-service/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateContentKeepsFormat(t *testing.T) {
+	s := suite.NewSuite(t, config.MustLoad(configPath))
+	ctx := t.Context()
+	userId := int(gofakeit.Uint16()) + 1
+	postId := createPost(t, s, userId, models.FormatMarkdown, "# title")
+
+	_, err := s.Post.Update(
+		ctx,
+		userId,
+		postId,
+		"",
+		"**bold** text",
+		"",
+		nil,
+		models.UpdateMask{Content: true},
+		nil,
+		nil,
+		false,
+		0,
+	)
+	require.NoError(t, err)
+
+	post, err := s.Post.Get(ctx, models.Viewer{Id: userId}, postId)
+	require.NoError(t, err)
+	require.Equal(t, models.FormatMarkdown, post.ContentFormat)
+	require.Equal(t, "**bold** text", post.Content)
+	require.Contains(t, post.Rendered.HTML, "<strong>bold</strong>")
+}
+
+// TestWriteStaleVersion writes the post with the version which was
+// already changed by another update
+func TestWriteStaleVersion(t *testing.T) {
+	s := suite.NewSuite(t, config.MustLoad(configPath))
+	ctx := t.Context()
+	userId := int(gofakeit.Uint16()) + 1
+	viewer := models.Viewer{Id: userId}
+	postId := createPost(t, s, userId, models.FormatPlain, gofakeit.Sentence(10))
+
+	post, err := s.Post.Get(ctx, viewer, postId)
+	require.NoError(t, err)
+	version := post.Version
+
+	_, err = s.Post.Update(
+		ctx, userId, postId, "first", "", "", nil,
+		models.UpdateMask{Header: true}, nil, nil, false, version,
+	)
+	require.NoError(t, err)
+
+	_, err = s.Post.Update(
+		ctx, userId, postId, "second", "", "", nil,
+		models.UpdateMask{Header: true}, nil, nil, false, version,
+	)
+	require.ErrorIs(t, err, posts.ErrConflict)
+	require.ErrorIs(t, s.Post.Delete(ctx, postId, userId, version), posts.ErrConflict)
+
+	post, err = s.Post.Get(ctx, viewer, postId)
+	require.NoError(t, err)
+	require.Equal(t, "first", post.Header)
+	require.Equal(t, version+1, post.Version)
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/IlianBuh/Post-service/internal/config"
+	"github.com/IlianBuh/Post-service/internal/domain/models"
+	"github.com/IlianBuh/Post-service/internal/service/reactions"
+	"github.com/IlianBuh/Post-service/tests/suite"
+	"github.com/brianvoe/gofakeit"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReactSwitchKind replaces reaction of the user with another kind
+// and checks that counters of both kinds follow
+func TestReactSwitchKind(t *testing.T) {
+	cfg := config.MustLoad(configPath)
+	require.GreaterOrEqual(t, len(cfg.Reactions.Kinds), 2)
+	s := suite.NewSuite(t, cfg)
+	ctx := t.Context()
+	first, second := cfg.Reactions.Kinds[0], cfg.Reactions.Kinds[1]
+
+	userId := int(gofakeit.Uint16()) + 1
+	otherId := userId + 1
+	postId := createPost(t, s, userId, models.FormatPlain, gofakeit.Sentence(10))
+
+	counts, err := s.Reaction.React(ctx, models.Viewer{Id: userId}, postId, first)
+	require.NoError(t, err)
+	require.Equal(t, []models.ReactionCount{{Kind: first, Count: 1}}, counts)
+
+	// the same reaction twice is counted once
+	counts, err = s.Reaction.React(ctx, models.Viewer{Id: userId}, postId, first)
+	require.NoError(t, err)
+	require.Equal(t, []models.ReactionCount{{Kind: first, Count: 1}}, counts)
+
+	counts, err = s.Reaction.React(ctx, models.Viewer{Id: otherId}, postId, first)
+	require.NoError(t, err)
+	require.Equal(t, []models.ReactionCount{{Kind: first, Count: 2}}, counts)
+
+	counts, err = s.Reaction.React(ctx, models.Viewer{Id: userId}, postId, second)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.ReactionCount{
+		{Kind: first, Count: 1},
+		{Kind: second, Count: 1},
+	}, counts)
+
+	reactors, counts, _, err := s.Reaction.ListReactors(ctx, models.Viewer{}, postId, second, "", 0)
+	require.NoError(t, err)
+	require.Len(t, reactors, 1)
+	require.Equal(t, userId, reactors[0].UserId)
+	require.Len(t, counts, 2)
+
+	counts, err = s.Reaction.Unreact(ctx, otherId, postId)
+	require.NoError(t, err)
+	require.Equal(t, []models.ReactionCount{{Kind: second, Count: 1}}, counts)
+
+	// removing missing reaction keeps counters
+	counts, err = s.Reaction.Unreact(ctx, otherId, postId)
+	require.NoError(t, err)
+	require.Equal(t, []models.ReactionCount{{Kind: second, Count: 1}}, counts)
+}
+
+func TestReactInvalid(t *testing.T) {
+	cfg := config.MustLoad(configPath)
+	s := suite.NewSuite(t, cfg)
+	ctx := t.Context()
+	userId := int(gofakeit.Uint16()) + 1
+	postId := createPost(t, s, userId, models.FormatPlain, gofakeit.Sentence(10))
+
+	_, err := s.Reaction.React(ctx, models.Viewer{Id: userId}, postId, "no-such-kind")
+	require.ErrorIs(t, err, reactions.ErrInvalidKind)
+
+	_, err = s.Reaction.React(ctx, models.Viewer{Id: userId}, 0, cfg.Reactions.Kinds[0])
+	require.ErrorIs(t, err, reactions.ErrNotFound)
+}
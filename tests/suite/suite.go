@@ -7,16 +7,23 @@ import (
 	"testing"
 
 	"github.com/IlianBuh/Post-service/internal/config"
+	"github.com/IlianBuh/Post-service/internal/lib/hashtags"
+	"github.com/IlianBuh/Post-service/internal/service/bookmarks"
+	"github.com/IlianBuh/Post-service/internal/service/comments"
 	eventworker "github.com/IlianBuh/Post-service/internal/service/event-worker"
 	"github.com/IlianBuh/Post-service/internal/service/posts"
+	"github.com/IlianBuh/Post-service/internal/service/reactions"
 	"github.com/IlianBuh/Post-service/internal/storage/postgres"
 	"github.com/IlianBuh/Post-service/internal/transport/kafka"
 	"github.com/IlianBuh/Post-service/tests/mocks"
 )
 
 type Suite struct {
-	Post *posts.PostService
-	ctx  context.Context
+	Post     *posts.PostService
+	Comment  *comments.CommentService
+	Reaction *reactions.ReactionService
+	Bookmark *bookmarks.BookmarkService
+	ctx      context.Context
 }
 
 func NewSuite(t *testing.T, cfg *config.Config) *Suite {
@@ -42,7 +49,31 @@ func NewSuite(t *testing.T, cfg *config.Config) *Suite {
 	postService := posts.New(
 		slog.New(
 			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		), repo, repo, repo, cfg.GRPC.Timeout.Duration, usrPrvdr,
+		), repo, repo, repo, repo, repo, repo, cfg.GRPC.Timeout.Duration, usrPrvdr,
+		hashtags.Options{
+			MaxThemes:  cfg.Hashtags.MaxThemes,
+			MinLength:  cfg.Hashtags.MinLength,
+			SplitWords: cfg.Hashtags.SplitWords,
+		},
+		cfg.Purger.IdempotencyTTL.Duration,
+	)
+
+	commentService := comments.New(
+		slog.New(
+			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		), repo, repo, repo, usrPrvdr, cfg.GRPC.Timeout.Duration,
+	)
+
+	reactionService := reactions.New(
+		slog.New(
+			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		), repo, repo, usrPrvdr, cfg.Reactions.Kinds, cfg.GRPC.Timeout.Duration,
+	)
+
+	bookmarkService := bookmarks.New(
+		slog.New(
+			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		), repo, repo, usrPrvdr, cfg.GRPC.Timeout.Duration,
 	)
 
 	// TODO : init kafka producer
@@ -76,8 +107,11 @@ func NewSuite(t *testing.T, cfg *config.Config) *Suite {
 	worker.Start(context.Background())
 
 	s := &Suite{
-		Post: postService,
-		ctx:  context.Background(),
+		Post:     postService,
+		Comment:  commentService,
+		Reaction: reactionService,
+		Bookmark: bookmarkService,
+		ctx:      context.Background(),
 	}
 
 	return s
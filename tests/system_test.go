@@ -6,14 +6,17 @@ import (
 	"time"
 
 	"github.com/IlianBuh/Post-service/internal/config"
+	"github.com/IlianBuh/Post-service/internal/domain/models"
 	"github.com/IlianBuh/Post-service/tests/suite"
 	userinfov1 "github.com/IlianBuh/SSO_Protobuf/gen/go/userinfo"
 	"github.com/brianvoe/gofakeit"
 	"github.com/stretchr/testify/require"
 )
 
+const configPath = "/home/il/Pet-project/Post-Service/config/config.json"
+
 func TestSystem(t *testing.T) {
-	cfg := config.MustLoad("/home/il/Pet-project/Post-Service/config/config.json")
+	cfg := config.MustLoad(configPath)
 	s := suite.NewSuite(t, cfg)
 	u, err := suite.NewUserClient("localhost:20202")
 	require.NoError(t, err)
@@ -33,7 +36,13 @@ func TestSystem(t *testing.T) {
 			user.User.Login,
 			gofakeit.Sentence(int((rand.Uint32()%20)+5)),
 			gofakeit.Paragraph(int(rand.Uint32()%2+1), 3, int((rand.Uint32()%25)+10), " "),
+			models.FormatPlain,
 			generateThemes(),
+			false,
+			models.StatusPublished,
+			time.Time{},
+			models.VisibilityPublic,
+			"",
 		)
 	}
 